@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/s-nix/mk2i18n/message"
 	"github.com/s-nix/mk2i18n/parser"
@@ -16,17 +17,91 @@ import (
 //	    Input
 //	-------------
 //	    .properties (Java .properties files)
-//	    .json       (JSON files)
-//	    .xml        (XML files)
+//	    .json       (JSON files, i18next JSON, or Chrome extension messages)
+//	    .xml        (XML files, Android string resources, or Java XML properties)
 //	    .toml       (TOML files)
 //	    .yaml       (YAML files)
+//	    .po, .pot   (gettext PO files and templates)
+//	    .mo         (compiled gettext MO files)
+//	    .xlf/.xliff (XLIFF 1.2 and 2.0 files)
+//	    .strings    (Apple strings files)
+//	    .stringsdict (Apple plural rule property lists)
+//	    .xcstrings  (Xcode string catalogs, one locale at a time)
+//	    .arb        (Flutter application resource bundles)
+//	    .resx       (.NET resource files)
+//	    .ts         (Qt Linguist translation files)
+//	    .ftl        (Project Fluent resources)
+//	    .csv, .tsv  (spreadsheets with configurable columns, one locale column at a time)
+//	    .xlsx       (Excel workbooks, one sheet with the same columns as .csv)
 //
 //	    Output
 //	--------------
-//	    .json       (JSON file in go-i18n format)
+//	    .json       (JSON file in go-i18n format, i18next JSON, or Chrome extension messages)
 //	    .toml       (TOML file in go-i18n format)
 //	    .yaml       (YAML file in go-i18n format)
+//	    .po         (gettext PO file)
+//	    .xlf/.xliff (XLIFF 1.2 or 2.0 file)
+//	    .xml        (Android string resource file)
+//	    .strings    (Apple strings file)
+//	    .stringsdict (Apple plural rule property list)
+//	    .xcstrings  (Xcode string catalog, merged into the existing file)
+//	    .arb        (Flutter application resource bundle)
+//	    .resx       (.NET resource file)
+//	    .ts         (Qt Linguist translation file)
+//	    .csv, .tsv  (spreadsheet with configurable columns)
+//	    .properties (Java .properties file, UTF-8 or ASCII with \u escapes)
 func Convert(inFile string, outFile string) error {
+	return ConvertWithOptions(inFile, outFile, Options{})
+}
+
+// Options configures how ConvertWithOptions reads and writes files.
+// The zero value gives the same behavior as Convert.
+type Options struct {
+	// Flatten controls how nested JSON, TOML and YAML input is flattened into messages.
+	Flatten parser.FlattenOptions
+
+	// Unflatten writes .json, .toml and .yaml output as plain nested data, rebuilt from the message IDs,
+	// instead of go-i18n message files. It does not apply to i18next and Chrome JSON output.
+	Unflatten bool
+
+	// Properties controls the encoding of Java .properties files and which of their comments become descriptions.
+	Properties parser.PropertiesOptions
+
+	// PO controls how gettext PO and MO files are read and how PO files are written.
+	PO parser.POOptions
+
+	// XLIFF controls how XLIFF files are read and written.
+	XLIFF parser.XLIFFOptions
+
+	// Android controls how Android string resources are written.
+	Android parser.AndroidOptions
+
+	// XCStrings controls which locale of an Xcode string catalog is read or written.
+	XCStrings parser.XCStringsOptions
+
+	// ARB controls how Flutter ARB files are written.
+	ARB parser.ARBOptions
+
+	// TS controls how Qt Linguist translation files are read and written.
+	TS parser.TSOptions
+
+	// CSV controls the column layout and locale of CSV and TSV spreadsheets and XLSX workbooks,
+	// and the sheet read from workbooks. The field separator is set from the file extension.
+	CSV parser.CSVOptions
+
+	// I18next controls how i18next JSON files are read and written.
+	I18next parser.I18nextOptions
+
+	// JSONFormat selects how .json files are read and written. By default the input format is detected
+	// from the file content and the output is a go-i18n message file.
+	JSONFormat parser.JSONFormat
+
+	// XMLFormat selects how .xml input is read. By default the format is detected from the file content.
+	XMLFormat parser.XMLFormat
+}
+
+// ConvertWithOptions converts the input file to the output file format like Convert, using the given options.
+func ConvertWithOptions(inFile string, outFile string, opts Options) error {
 	inExtension := filepath.Ext(inFile)
 	outExtension := filepath.Ext(outFile)
 
@@ -35,27 +110,139 @@ func Convert(inFile string, outFile string) error {
 	var err error
 	switch inExtension {
 	case ".properties":
-		messages, err = parser.FromProperties(inFile)
+		messages, err = parser.FromPropertiesWithOptions(inFile, opts.Properties)
 		if err != nil {
 			return err
 		}
 	case ".json":
-		messages, err = parser.FromJSON(inFile)
+		format := opts.JSONFormat
+		if format == parser.JSONFormatAuto {
+			format, err = parser.DetectJSONFormat(inFile)
+			if err != nil {
+				return err
+			}
+		}
+		switch format {
+		case parser.JSONFormatGeneric:
+			messages, err = parser.FromJSONWithOptions(inFile, opts.Flatten)
+		case parser.JSONFormatI18next:
+			messages, err = parser.FromI18nextWithOptions(inFile, opts.I18next)
+		case parser.JSONFormatChrome:
+			messages, err = parser.FromChromeMessages(inFile)
+		default:
+			return fmt.Errorf("unsupported JSON format: %s", opts.JSONFormat)
+		}
 		if err != nil {
 			return err
 		}
 	case ".xml":
-		messages, err = parser.FromXML(inFile)
+		format := opts.XMLFormat
+		if format == parser.XMLFormatAuto {
+			format, err = parser.DetectXMLFormat(inFile)
+			if err != nil {
+				return err
+			}
+		}
+		switch format {
+		case parser.XMLFormatGeneric:
+			messages, err = parser.FromXML(inFile)
+		case parser.XMLFormatAndroid:
+			messages, err = parser.FromAndroidXML(inFile)
+		case parser.XMLFormatProperties:
+			messages, err = parser.FromPropertiesXML(inFile)
+		default:
+			return fmt.Errorf("unsupported XML format: %s", format)
+		}
 		if err != nil {
 			return err
 		}
 	case ".toml":
-		messages, err = parser.FromTOML(inFile)
+		messages, err = parser.FromTOMLWithOptions(inFile, opts.Flatten)
 		if err != nil {
 			return err
 		}
 	case ".yaml", ".yml":
-		messages, err = parser.FromYAML(inFile)
+		messages, err = parser.FromYAMLWithOptions(inFile, opts.Flatten)
+		if err != nil {
+			return err
+		}
+	case ".po", ".pot":
+		messages, err = parser.FromPOWithOptions(inFile, opts.PO)
+		if err != nil {
+			return err
+		}
+	case ".mo":
+		messages, err = parser.FromMOWithOptions(inFile, opts.PO)
+		if err != nil {
+			return err
+		}
+	case ".xlf", ".xliff":
+		messages, err = parser.FromXLIFFWithOptions(inFile, opts.XLIFF)
+		if err != nil {
+			return err
+		}
+	case ".strings":
+		messages, err = parser.FromAppleStrings(inFile)
+		if err != nil {
+			return err
+		}
+	case ".stringsdict":
+		messages, err = parser.FromStringsdict(inFile)
+		if err != nil {
+			return err
+		}
+	case ".xcstrings":
+		messages, err = parser.FromXCStringsWithOptions(inFile, opts.XCStrings)
+		if err != nil {
+			return err
+		}
+	case ".arb":
+		messages, err = parser.FromARB(inFile)
+		if err != nil {
+			return err
+		}
+		// The @@locale of the bundle is the output locale unless one is given.
+		locale, err := parser.ARBLocale(inFile)
+		if err != nil {
+			return err
+		}
+		if locale != "" {
+			opts = opts.withDefaultLocale(locale)
+		}
+	case ".resx":
+		messages, err = parser.FromRESX(inFile)
+		if err != nil {
+			return err
+		}
+	case ".ts":
+		messages, err = parser.FromTSWithOptions(inFile, opts.TS)
+		if err != nil {
+			return err
+		}
+		// The language of the translation file is the output locale unless one is given.
+		locale, err := parser.TSLanguage(inFile)
+		if err != nil {
+			return err
+		}
+		if locale != "" {
+			opts = opts.withDefaultLocale(locale)
+		}
+	case ".ftl":
+		messages, err = parser.FromFluent(inFile)
+		if err != nil {
+			return err
+		}
+	case ".csv", ".tsv":
+		csvOpts := opts.CSV
+		if inExtension == ".tsv" {
+			csvOpts.Comma = '\t'
+		}
+		messages, err = parser.FromCSVWithOptions(inFile, csvOpts)
+		if err != nil {
+			return err
+		}
+	case ".xlsx":
+		messages, err = parser.FromXLSXWithOptions(inFile, opts.CSV)
 		if err != nil {
 			return err
 		}
@@ -67,17 +254,102 @@ func Convert(inFile string, outFile string) error {
 	var output string
 	switch outExtension {
 	case ".json":
-		output, err = parser.ToJSON(messages)
+		switch opts.JSONFormat {
+		case parser.JSONFormatAuto, parser.JSONFormatGeneric:
+			if opts.Unflatten {
+				output, err = parser.ToNestedJSON(messages)
+			} else {
+				output, err = parser.ToJSON(messages)
+			}
+		case parser.JSONFormatI18next:
+			output, err = parser.ToI18nextWithOptions(messages, opts.I18next)
+		case parser.JSONFormatChrome:
+			output, err = parser.ToChromeMessages(messages)
+		default:
+			return fmt.Errorf("unsupported JSON format: %s", opts.JSONFormat)
+		}
 		if err != nil {
 			return err
 		}
 	case ".toml":
-		output, err = parser.ToTOML(messages)
+		if opts.Unflatten {
+			output, err = parser.ToNestedTOML(messages)
+		} else {
+			output, err = parser.ToTOML(messages)
+		}
 		if err != nil {
 			return err
 		}
 	case ".yaml", ".yml":
-		output, err = parser.ToYAML(messages)
+		if opts.Unflatten {
+			output, err = parser.ToNestedYAML(messages)
+		} else {
+			output, err = parser.ToYAML(messages)
+		}
+		if err != nil {
+			return err
+		}
+	case ".po":
+		output, err = parser.ToPOWithOptions(messages, opts.PO)
+		if err != nil {
+			return err
+		}
+	case ".xlf", ".xliff":
+		output, err = parser.ToXLIFFWithOptions(messages, opts.XLIFF)
+		if err != nil {
+			return err
+		}
+	case ".xml":
+		output, err = parser.ToAndroidXMLWithOptions(messages, opts.Android)
+		if err != nil {
+			return err
+		}
+	case ".strings":
+		output, err = parser.ToAppleStrings(messages)
+		if err != nil {
+			return err
+		}
+	case ".stringsdict":
+		output, err = parser.ToStringsdict(messages)
+		if err != nil {
+			return err
+		}
+	case ".xcstrings":
+		// String catalogs hold every locale, so the messages are merged into the existing catalog.
+		catalog, err := os.ReadFile(outFile)
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		output, err = parser.ToXCStringsWithOptions(messages, catalog, opts.XCStrings)
+		if err != nil {
+			return err
+		}
+	case ".arb":
+		output, err = parser.ToARBWithOptions(messages, opts.ARB)
+		if err != nil {
+			return err
+		}
+	case ".resx":
+		output, err = parser.ToRESX(messages)
+		if err != nil {
+			return err
+		}
+	case ".ts":
+		output, err = parser.ToTSWithOptions(messages, opts.TS)
+		if err != nil {
+			return err
+		}
+	case ".csv", ".tsv":
+		csvOpts := opts.CSV
+		if outExtension == ".tsv" {
+			csvOpts.Comma = '\t'
+		}
+		output, err = parser.ToCSVWithOptions(messages, csvOpts)
+		if err != nil {
+			return err
+		}
+	case ".properties":
+		output, err = parser.ToPropertiesWithOptions(messages, opts.Properties)
 		if err != nil {
 			return err
 		}
@@ -91,3 +363,83 @@ func Convert(inFile string, outFile string) error {
 	}
 	return nil
 }
+
+// ConvertAllLocales converts every locale of a multi-locale input file, such as an Xcode string catalog or
+// a spreadsheet with one column per locale, into its own output file and returns the paths of the written files.
+// The outTemplate is the output path with {locale} in place of the locale, such as active.{locale}.toml.
+// Each conversion uses opts with the locale set as the locale to read and write.
+// Missing output directories are created.
+func ConvertAllLocales(inFile string, outTemplate string, opts Options) ([]string, error) {
+	if !strings.Contains(outTemplate, "{locale}") {
+		return nil, fmt.Errorf("output template %q does not contain {locale}", outTemplate)
+	}
+
+	var locales []string
+	var err error
+	switch inExtension := filepath.Ext(inFile); inExtension {
+	case ".xcstrings":
+		locales, err = parser.XCStringsLocales(inFile)
+		if err != nil {
+			return nil, err
+		}
+	case ".csv", ".tsv":
+		csvOpts := opts.CSV
+		if inExtension == ".tsv" {
+			csvOpts.Comma = '\t'
+		}
+		locales, err = parser.CSVLocales(inFile, csvOpts)
+		if err != nil {
+			return nil, err
+		}
+	case ".xlsx":
+		locales, err = parser.XLSXLocales(inFile, opts.CSV)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("input file extension %s does not hold multiple locales", inExtension)
+	}
+
+	var written []string
+	for _, locale := range locales {
+		outFile := strings.ReplaceAll(outTemplate, "{locale}", locale)
+		if err := os.MkdirAll(filepath.Dir(outFile), os.ModePerm); err != nil {
+			return written, err
+		}
+		localeOpts := opts
+		localeOpts.XCStrings.Locale = locale
+		localeOpts.PO.Locale = locale
+		localeOpts.XLIFF.TargetLanguage = locale
+		localeOpts.ARB.Locale = locale
+		localeOpts.TS.Locale = locale
+		localeOpts.CSV.Locale = locale
+		if err := ConvertWithOptions(inFile, outFile, localeOpts); err != nil {
+			return written, fmt.Errorf("locale %s: %w", locale, err)
+		}
+		written = append(written, outFile)
+	}
+	return written, nil
+}
+
+// withDefaultLocale returns a copy of opts with locale as the locale of every format that has none.
+func (opts Options) withDefaultLocale(locale string) Options {
+	if opts.PO.Locale == "" {
+		opts.PO.Locale = locale
+	}
+	if opts.XLIFF.TargetLanguage == "" {
+		opts.XLIFF.TargetLanguage = locale
+	}
+	if opts.XCStrings.Locale == "" {
+		opts.XCStrings.Locale = locale
+	}
+	if opts.ARB.Locale == "" {
+		opts.ARB.Locale = locale
+	}
+	if opts.TS.Locale == "" {
+		opts.TS.Locale = locale
+	}
+	if opts.CSV.Locale == "" {
+		opts.CSV.Locale = locale
+	}
+	return opts
+}
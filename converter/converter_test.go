@@ -1,9 +1,12 @@
 package converter
 
 import (
+	"archive/zip"
 	"os"
+	"path/filepath"
 	"testing"
 
+	"github.com/s-nix/mk2i18n/parser"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -118,6 +121,34 @@ farewell.other:
   description: ""
   other: Goodbye
 
+`
+	expectedMessageJSON = `{
+  "farewell": {
+	"description": "A farewell message",
+	"other": "Goodbye"
+  },
+  "greeting": {
+	"description": "A greeting message",
+	"other": "Hello"
+  }
+}`
+	expectedMessageTOML = `[farewell]
+description = "A farewell message"
+other = "Goodbye"
+
+[greeting]
+description = "A greeting message"
+other = "Hello"
+
+`
+	expectedMessageYAML = `farewell:
+  description: A farewell message
+  other: Goodbye
+
+greeting:
+  description: A greeting message
+  other: Hello
+
 `
 	expectedAltYAML = `farewell.description:
   description: ""
@@ -203,7 +234,7 @@ func TestConvertTOMLToJSON(t *testing.T) {
 	assert.NoError(t, err, "Failed to read output JSON file")
 
 	// Compare the output with expected JSON content
-	assert.JSONEq(t, expectedJSON, string(outputData), "JSON output did not match expected")
+	assert.JSONEq(t, expectedMessageJSON, string(outputData), "JSON output did not match expected")
 	err = tmpFile.Close()
 	assert.NoError(t, err)
 
@@ -239,7 +270,7 @@ func TestConvertJSONToJSON(t *testing.T) {
 	assert.NoError(t, err, "Failed to read output JSON file")
 
 	// Compare the output with expected JSON content
-	assert.JSONEq(t, expectedJSON, string(outputData), "JSON output did not match expected")
+	assert.JSONEq(t, expectedMessageJSON, string(outputData), "JSON output did not match expected")
 	err = tmpFile.Close()
 	assert.NoError(t, err)
 
@@ -311,7 +342,7 @@ func TestConvertYAMLToJSON(t *testing.T) {
 	assert.NoError(t, err, "Failed to read output JSON file")
 
 	// Compare the output with expected JSON content
-	assert.JSONEq(t, expectedJSON, string(outputData), "JSON output did not match expected")
+	assert.JSONEq(t, expectedMessageJSON, string(outputData), "JSON output did not match expected")
 	err = tmpFile.Close()
 	assert.NoError(t, err)
 
@@ -384,7 +415,7 @@ func TestConvertTOMLToTOML(t *testing.T) {
 	assert.NoError(t, err, "Failed to read output TOML file")
 
 	// Compare the output with expected TOML content
-	assert.Equal(t, expectedAltTOML, string(outputData), "TOML output did not match expected")
+	assert.Equal(t, expectedMessageTOML, string(outputData), "TOML output did not match expected")
 	err = tmpFile.Close()
 	assert.NoError(t, err)
 	err = tmpOutputFile.Close()
@@ -418,7 +449,7 @@ func TestConvertJSONToTOML(t *testing.T) {
 	assert.NoError(t, err, "Failed to read output TOML file")
 
 	// Compare the output with expected TOML content
-	assert.Equal(t, expectedAltTOML, string(outputData), "TOML output did not match expected")
+	assert.Equal(t, expectedMessageTOML, string(outputData), "TOML output did not match expected")
 	err = tmpFile.Close()
 	assert.NoError(t, err)
 
@@ -490,7 +521,7 @@ func TestConvertYAMLToTOML(t *testing.T) {
 	assert.NoError(t, err, "Failed to read output TOML file")
 
 	// Compare the output with expected TOML content
-	assert.Equal(t, expectedAltTOML, string(outputData), "TOML output did not match expected")
+	assert.Equal(t, expectedMessageTOML, string(outputData), "TOML output did not match expected")
 	err = tmpFile.Close()
 	assert.NoError(t, err)
 
@@ -563,7 +594,7 @@ func TestConvertTOMLToYAML(t *testing.T) {
 	assert.NoError(t, err, "Failed to read output YAML file")
 
 	// Compare the output with expected YAML content
-	assert.Equal(t, expectedAltYAML, string(outputData), "YAML output did not match expected")
+	assert.Equal(t, expectedMessageYAML, string(outputData), "YAML output did not match expected")
 	err = tmpFile.Close()
 	assert.NoError(t, err)
 
@@ -599,7 +630,7 @@ func TestConvertJSONToYAML(t *testing.T) {
 	assert.NoError(t, err, "Failed to read output YAML file")
 
 	// Compare the output with expected YAML content
-	assert.Equal(t, expectedAltYAML, string(outputData), "YAML output did not match expected")
+	assert.Equal(t, expectedMessageYAML, string(outputData), "YAML output did not match expected")
 	err = tmpFile.Close()
 	assert.NoError(t, err)
 
@@ -671,7 +702,997 @@ func TestConvertYAMLToYAML(t *testing.T) {
 	assert.NoError(t, err, "Failed to read output YAML file")
 
 	// Compare the output with expected YAML content
-	assert.Equal(t, expectedAltYAML, string(outputData), "YAML output did not match expected")
+	assert.Equal(t, expectedMessageYAML, string(outputData), "YAML output did not match expected")
+	err = tmpFile.Close()
+	assert.NoError(t, err)
+
+	err = tmpOutputFile.Close()
+	assert.NoError(t, err)
+}
+
+// Options tests
+func TestConvertWithOptionsPluralDetection(t *testing.T) {
+	pluralJSON := `{
+  "items": {
+	"one": "{{.Count}} item",
+	"other": "{{.Count}} items"
+  }
+}`
+	tmpFile, err := os.CreateTemp("", "test_input_*.json")
+	assert.NoError(t, err)
+
+	defer func(name string) {
+		err := os.Remove(name)
+		assert.NoError(t, err, "Failed to remove input temporary file")
+	}(tmpFile.Name())
+
+	_, err = tmpFile.WriteString(pluralJSON)
+	assert.NoError(t, err)
+
+	tmpOutputFile, err := os.CreateTemp("", "test_output_*.yaml")
+	assert.NoError(t, err)
+	defer func(name string) {
+		err := os.Remove(name)
+		assert.NoError(t, err, "Failed to remove output temporary file")
+	}(tmpOutputFile.Name())
+
+	err = Convert(tmpFile.Name(), tmpOutputFile.Name())
+	assert.NoError(t, err, "Conversion failed")
+
+	outputData, err := os.ReadFile(tmpOutputFile.Name())
+	assert.NoError(t, err, "Failed to read output YAML file")
+
+	expectedPluralYAML := `items:
+  description: ""
+  one: '{{.Count}} item'
+  other: '{{.Count}} items'
+
+`
+	assert.Equal(t, expectedPluralYAML, string(outputData), "YAML output did not match expected")
+
+	err = ConvertWithOptions(tmpFile.Name(), tmpOutputFile.Name(), Options{
+		Flatten: parser.FlattenOptions{DisablePluralDetection: true},
+	})
+	assert.NoError(t, err, "Conversion failed")
+
+	outputData, err = os.ReadFile(tmpOutputFile.Name())
+	assert.NoError(t, err, "Failed to read output YAML file")
+
+	expectedFlatYAML := `items.one:
+  description: ""
+  other: '{{.Count}} item'
+
+items.other:
+  description: ""
+  other: '{{.Count}} items'
+
+`
+	assert.Equal(t, expectedFlatYAML, string(outputData), "YAML output did not match expected")
+	err = tmpFile.Close()
+	assert.NoError(t, err)
+
+	err = tmpOutputFile.Close()
+	assert.NoError(t, err)
+}
+
+func TestConvertPOToTOML(t *testing.T) {
+	poContent := `msgid ""
+msgstr ""
+"Language: de\n"
+"Plural-Forms: nplurals=2; plural=(n != 1);\n"
+
+#. A greeting message
+msgid "greeting"
+msgstr "Hallo"
+
+msgid "items"
+msgid_plural "items"
+msgstr[0] "{{.Count}} Artikel"
+msgstr[1] "{{.Count}} Artikel insgesamt"
+`
+	tmpFile, err := os.CreateTemp("", "test_input_*.po")
+	assert.NoError(t, err)
+
+	defer func(name string) {
+		err := os.Remove(name)
+		assert.NoError(t, err, "Failed to remove input temporary file")
+	}(tmpFile.Name())
+
+	_, err = tmpFile.WriteString(poContent)
+	assert.NoError(t, err)
+
+	tmpOutputFile, err := os.CreateTemp("", "test_output_*.toml")
+	assert.NoError(t, err)
+	defer func(name string) {
+		err := os.Remove(name)
+		assert.NoError(t, err, "Failed to remove output temporary file")
+	}(tmpOutputFile.Name())
+
+	err = Convert(tmpFile.Name(), tmpOutputFile.Name())
+	assert.NoError(t, err, "Conversion failed")
+
+	outputData, err := os.ReadFile(tmpOutputFile.Name())
+	assert.NoError(t, err, "Failed to read output TOML file")
+
+	expectedPOTOML := `[greeting]
+description = "A greeting message"
+other = "Hallo"
+
+[items]
+description = ""
+one = "{{.Count}} Artikel"
+other = "{{.Count}} Artikel insgesamt"
+
+`
+	assert.Equal(t, expectedPOTOML, string(outputData), "TOML output did not match expected")
+	err = tmpFile.Close()
+	assert.NoError(t, err)
+
+	err = tmpOutputFile.Close()
+	assert.NoError(t, err)
+}
+
+func TestConvertJSONToPO(t *testing.T) {
+	tmpFile, err := os.CreateTemp("", "test_input_*.json")
+	assert.NoError(t, err)
+
+	defer func(name string) {
+		err := os.Remove(name)
+		assert.NoError(t, err, "Failed to remove input temporary file")
+	}(tmpFile.Name())
+
+	_, err = tmpFile.WriteString(jsonContent)
+	assert.NoError(t, err)
+
+	tmpOutputFile, err := os.CreateTemp("", "test_output_*.po")
+	assert.NoError(t, err)
+	defer func(name string) {
+		err := os.Remove(name)
+		assert.NoError(t, err, "Failed to remove output temporary file")
+	}(tmpOutputFile.Name())
+
+	err = ConvertWithOptions(tmpFile.Name(), tmpOutputFile.Name(), Options{
+		PO: parser.POOptions{Locale: "fr", IDAsContext: true},
+	})
+	assert.NoError(t, err, "Conversion failed")
+
+	outputData, err := os.ReadFile(tmpOutputFile.Name())
+	assert.NoError(t, err, "Failed to read output PO file")
+
+	expectedPO := `msgid ""
+msgstr ""
+"Language: fr\n"
+"MIME-Version: 1.0\n"
+"Content-Type: text/plain; charset=UTF-8\n"
+"Content-Transfer-Encoding: 8bit\n"
+"Plural-Forms: nplurals=2; plural=(n > 1);\n"
+
+#. A farewell message
+msgctxt "farewell"
+msgid "Goodbye"
+msgstr "Goodbye"
+
+#. A greeting message
+msgctxt "greeting"
+msgid "Hello"
+msgstr "Hello"
+`
+	assert.Equal(t, expectedPO, string(outputData), "PO output did not match expected")
+	err = tmpFile.Close()
+	assert.NoError(t, err)
+
+	err = tmpOutputFile.Close()
+	assert.NoError(t, err)
+}
+
+func TestConvertXLIFFToJSON(t *testing.T) {
+	xliffContent := `<?xml version="1.0" encoding="UTF-8"?>
+<xliff version="1.2" xmlns="urn:oasis:names:tc:xliff:document:1.2">
+  <file original="app" source-language="en" target-language="en" datatype="plaintext">
+    <body>
+      <trans-unit id="greeting">
+        <source>Hello</source>
+        <note>A greeting message</note>
+      </trans-unit>
+      <trans-unit id="farewell">
+        <source>Bye</source>
+        <target>Goodbye</target>
+        <note>A farewell message</note>
+      </trans-unit>
+    </body>
+  </file>
+</xliff>`
+	tmpFile, err := os.CreateTemp("", "test_input_*.xlf")
+	assert.NoError(t, err)
+
+	defer func(name string) {
+		err := os.Remove(name)
+		assert.NoError(t, err, "Failed to remove input temporary file")
+	}(tmpFile.Name())
+
+	_, err = tmpFile.WriteString(xliffContent)
+	assert.NoError(t, err)
+
+	tmpOutputFile, err := os.CreateTemp("", "test_output_*.json")
+	assert.NoError(t, err)
+	defer func(name string) {
+		err := os.Remove(name)
+		assert.NoError(t, err, "Failed to remove output temporary file")
+	}(tmpOutputFile.Name())
+
+	err = Convert(tmpFile.Name(), tmpOutputFile.Name())
+	assert.NoError(t, err, "Conversion failed")
+
+	outputData, err := os.ReadFile(tmpOutputFile.Name())
+	assert.NoError(t, err, "Failed to read output JSON file")
+
+	assert.JSONEq(t, expectedMessageJSON, string(outputData), "JSON output did not match expected")
+	err = tmpFile.Close()
+	assert.NoError(t, err)
+
+	err = tmpOutputFile.Close()
+	assert.NoError(t, err)
+}
+
+func TestConvertAndroidXMLToYAML(t *testing.T) {
+	androidContent := `<resources>
+	<!-- A farewell message -->
+	<string name="farewell">Goodbye</string>
+	<!-- A greeting message -->
+	<string name="greeting">Hello</string>
+	<string name="app_name" translatable="false">App</string>
+</resources>`
+	tmpFile, err := os.CreateTemp("", "test_input_*.xml")
+	assert.NoError(t, err)
+
+	defer func(name string) {
+		err := os.Remove(name)
+		assert.NoError(t, err, "Failed to remove input temporary file")
+	}(tmpFile.Name())
+
+	_, err = tmpFile.WriteString(androidContent)
+	assert.NoError(t, err)
+
+	tmpOutputFile, err := os.CreateTemp("", "test_output_*.yaml")
+	assert.NoError(t, err)
+	defer func(name string) {
+		err := os.Remove(name)
+		assert.NoError(t, err, "Failed to remove output temporary file")
+	}(tmpOutputFile.Name())
+
+	err = Convert(tmpFile.Name(), tmpOutputFile.Name())
+	assert.NoError(t, err, "Conversion failed")
+
+	outputData, err := os.ReadFile(tmpOutputFile.Name())
+	assert.NoError(t, err, "Failed to read output YAML file")
+
+	assert.Equal(t, expectedMessageYAML, string(outputData), "YAML output did not match expected")
+
+	err = ConvertWithOptions(tmpFile.Name(), tmpOutputFile.Name(), Options{XMLFormat: parser.XMLFormatGeneric})
+	assert.NoError(t, err, "Conversion failed")
+
+	outputData, err = os.ReadFile(tmpOutputFile.Name())
+	assert.NoError(t, err, "Failed to read output YAML file")
+	assert.Contains(t, string(outputData), "string.0:", "Generic XML output did not match expected")
+
+	err = tmpFile.Close()
+	assert.NoError(t, err)
+
+	err = tmpOutputFile.Close()
+	assert.NoError(t, err)
+}
+
+func TestConvertJSONToAndroidXML(t *testing.T) {
+	jsonContent := `{
+  "home": {"title": {"description": "Home screen title", "other": "Bob's {{.Arg1}}"}},
+  "items": {"one": "1 item", "other": "{{.Arg1}} items"}
+}`
+	tmpFile, err := os.CreateTemp("", "test_input_*.json")
+	assert.NoError(t, err)
+
+	defer func(name string) {
+		err := os.Remove(name)
+		assert.NoError(t, err, "Failed to remove input temporary file")
+	}(tmpFile.Name())
+
+	_, err = tmpFile.WriteString(jsonContent)
+	assert.NoError(t, err)
+
+	tmpOutputFile, err := os.CreateTemp("", "test_output_*.xml")
+	assert.NoError(t, err)
+	defer func(name string) {
+		err := os.Remove(name)
+		assert.NoError(t, err, "Failed to remove output temporary file")
+	}(tmpOutputFile.Name())
+
+	err = ConvertWithOptions(tmpFile.Name(), tmpOutputFile.Name(), Options{
+		Android: parser.AndroidOptions{NameMapping: map[string]string{"home.title": "homeTitle"}},
+	})
+	assert.NoError(t, err, "Conversion failed")
+
+	outputData, err := os.ReadFile(tmpOutputFile.Name())
+	assert.NoError(t, err, "Failed to read output XML file")
+
+	expected := `<?xml version="1.0" encoding="UTF-8"?>
+<resources>
+    <!-- Home screen title -->
+    <string name="homeTitle">Bob\'s %1$s</string>
+    <plurals name="items">
+        <item quantity="one">1 item</item>
+        <item quantity="other">%1$s items</item>
+    </plurals>
+</resources>
+`
+	assert.Equal(t, expected, string(outputData), "Android XML output did not match expected")
+
+	err = tmpFile.Close()
+	assert.NoError(t, err)
+
+	err = tmpOutputFile.Close()
+	assert.NoError(t, err)
+}
+
+func TestConvertStringsdictToAppleStrings(t *testing.T) {
+	tmpFile, err := os.CreateTemp("", "test_input_*.stringsdict")
+	assert.NoError(t, err)
+
+	defer func(name string) {
+		err := os.Remove(name)
+		assert.NoError(t, err, "Failed to remove input temporary file")
+	}(tmpFile.Name())
+
+	_, err = tmpFile.WriteString(`<?xml version="1.0" encoding="UTF-8"?>
+<plist version="1.0">
+<dict>
+	<key>items</key>
+	<dict>
+		<key>NSStringLocalizedFormatKey</key>
+		<string>%#@items@</string>
+		<key>items</key>
+		<dict>
+			<key>NSStringFormatSpecTypeKey</key>
+			<string>NSStringPluralRuleType</string>
+			<key>one</key>
+			<string>%d item</string>
+			<key>other</key>
+			<string>%d items</string>
+		</dict>
+	</dict>
+</dict>
+</plist>`)
+	assert.NoError(t, err)
+
+	tmpOutputFile, err := os.CreateTemp("", "test_output_*.strings")
+	assert.NoError(t, err)
+	defer func(name string) {
+		err := os.Remove(name)
+		assert.NoError(t, err, "Failed to remove output temporary file")
+	}(tmpOutputFile.Name())
+
+	err = Convert(tmpFile.Name(), tmpOutputFile.Name())
+	assert.NoError(t, err, "Conversion failed")
+
+	outputData, err := os.ReadFile(tmpOutputFile.Name())
+	assert.NoError(t, err, "Failed to read output strings file")
+
+	assert.Equal(t, "\"items\" = \"%1$lld items\";\n", string(outputData), "Strings output did not match expected")
+
+	err = tmpFile.Close()
+	assert.NoError(t, err)
+
+	err = tmpOutputFile.Close()
+	assert.NoError(t, err)
+}
+
+func TestConvertAllLocalesXCStrings(t *testing.T) {
+	tmpFile, err := os.CreateTemp("", "test_input_*.xcstrings")
+	assert.NoError(t, err)
+
+	defer func(name string) {
+		err := os.Remove(name)
+		assert.NoError(t, err, "Failed to remove input temporary file")
+	}(tmpFile.Name())
+
+	_, err = tmpFile.WriteString(`{
+  "sourceLanguage" : "en",
+  "strings" : {
+    "greeting" : {
+      "comment" : "A greeting message",
+      "localizations" : {
+        "de" : { "stringUnit" : { "state" : "translated", "value" : "Hallo" } },
+        "en" : { "stringUnit" : { "state" : "translated", "value" : "Hello" } }
+      }
+    }
+  },
+  "version" : "1.0"
+}`)
+	assert.NoError(t, err)
+
+	outDir, err := os.MkdirTemp("", "test_output_*")
+	assert.NoError(t, err)
+	defer func(name string) {
+		err := os.RemoveAll(name)
+		assert.NoError(t, err, "Failed to remove output temporary directory")
+	}(outDir)
+
+	written, err := ConvertAllLocales(tmpFile.Name(), filepath.Join(outDir, "{locale}", "active.{locale}.yaml"), Options{})
+	assert.NoError(t, err, "Conversion failed")
+	assert.Equal(t, []string{
+		filepath.Join(outDir, "de", "active.de.yaml"),
+		filepath.Join(outDir, "en", "active.en.yaml"),
+	}, written)
+
+	outputData, err := os.ReadFile(written[0])
+	assert.NoError(t, err, "Failed to read output YAML file")
+	assert.Equal(t, "greeting:\n  description: A greeting message\n  other: Hallo\n\n", string(outputData), "YAML output did not match expected")
+
+	_, err = ConvertAllLocales(tmpFile.Name(), filepath.Join(outDir, "active.yaml"), Options{})
+	assert.ErrorContains(t, err, "does not contain {locale}")
+
+	err = tmpFile.Close()
+	assert.NoError(t, err)
+}
+
+func TestConvertARBToPO(t *testing.T) {
+	tmpFile, err := os.CreateTemp("", "test_input_*.arb")
+	assert.NoError(t, err)
+
+	defer func(name string) {
+		err := os.Remove(name)
+		assert.NoError(t, err, "Failed to remove input temporary file")
+	}(tmpFile.Name())
+
+	_, err = tmpFile.WriteString(`{
+  "@@locale": "ru",
+  "files": "{count, plural, one{# файл} few{# файла} many{# файлов} other{# файла}}",
+  "@files": {"description": "Number of files"}
+}`)
+	assert.NoError(t, err)
+
+	tmpOutputFile, err := os.CreateTemp("", "test_output_*.po")
+	assert.NoError(t, err)
+	defer func(name string) {
+		err := os.Remove(name)
+		assert.NoError(t, err, "Failed to remove output temporary file")
+	}(tmpOutputFile.Name())
+
+	err = Convert(tmpFile.Name(), tmpOutputFile.Name())
+	assert.NoError(t, err, "Conversion failed")
+
+	outputData, err := os.ReadFile(tmpOutputFile.Name())
+	assert.NoError(t, err, "Failed to read output PO file")
+
+	expectedPO := `msgid ""
+msgstr ""
+"Language: ru\n"
+"MIME-Version: 1.0\n"
+"Content-Type: text/plain; charset=UTF-8\n"
+"Content-Transfer-Encoding: 8bit\n"
+"Plural-Forms: nplurals=3; plural=(n%10==1 && n%100!=11 ? 0 : n%10>=2 && n%10<=4 && (n%100<10 || n%100>=20) ? 1 : 2);\n"
+
+#. Number of files
+msgid "files"
+msgid_plural "files"
+msgstr[0] "{{.count}} файл"
+msgstr[1] "{{.count}} файла"
+msgstr[2] "{{.count}} файлов"
+`
+	assert.Equal(t, expectedPO, string(outputData), "PO output did not match expected")
+
+	err = tmpFile.Close()
+	assert.NoError(t, err)
+
+	err = tmpOutputFile.Close()
+	assert.NoError(t, err)
+}
+
+func TestConvertI18nextToTOML(t *testing.T) {
+	i18nextContent := `{
+  "friend": "A friend",
+  "friend_male": "A boyfriend",
+  "items_one": "{{count}} item",
+  "items_other": "{{count}} items",
+  "welcome": "Welcome, {{name}}! $t(friend)"
+}`
+	tmpFile, err := os.CreateTemp("", "test_input_*.json")
+	assert.NoError(t, err)
+
+	defer func(name string) {
+		err := os.Remove(name)
+		assert.NoError(t, err, "Failed to remove input temporary file")
+	}(tmpFile.Name())
+
+	_, err = tmpFile.WriteString(i18nextContent)
+	assert.NoError(t, err)
+
+	tmpOutputFile, err := os.CreateTemp("", "test_output_*.toml")
+	assert.NoError(t, err)
+	defer func(name string) {
+		err := os.Remove(name)
+		assert.NoError(t, err, "Failed to remove output temporary file")
+	}(tmpOutputFile.Name())
+
+	err = ConvertWithOptions(tmpFile.Name(), tmpOutputFile.Name(), Options{JSONFormat: parser.JSONFormatI18next})
+	assert.NoError(t, err, "Conversion failed")
+
+	outputData, err := os.ReadFile(tmpOutputFile.Name())
+	assert.NoError(t, err, "Failed to read output TOML file")
+
+	expectedTOML := `[friend]
+description = ""
+other = "A friend"
+
+["friend#male"]
+description = ""
+other = "A boyfriend"
+
+[items]
+description = ""
+one = "{{.count}} item"
+other = "{{.count}} items"
+
+[welcome]
+description = ""
+other = "Welcome, {{.name}}! A friend"
+
+`
+	assert.Equal(t, expectedTOML, string(outputData), "TOML output did not match expected")
+
+	err = tmpFile.Close()
+	assert.NoError(t, err)
+
+	err = tmpOutputFile.Close()
+	assert.NoError(t, err)
+}
+
+func TestConvertChromeMessagesToYAML(t *testing.T) {
+	chromeContent := `{
+  "farewell": {"message": "Goodbye", "description": "A farewell message"},
+  "greeting": {"message": "Hello", "description": "A greeting message"}
+}`
+	tmpFile, err := os.CreateTemp("", "test_input_*.json")
+	assert.NoError(t, err)
+
+	defer func(name string) {
+		err := os.Remove(name)
+		assert.NoError(t, err, "Failed to remove input temporary file")
+	}(tmpFile.Name())
+
+	_, err = tmpFile.WriteString(chromeContent)
+	assert.NoError(t, err)
+
+	tmpOutputFile, err := os.CreateTemp("", "test_output_*.yaml")
+	assert.NoError(t, err)
+	defer func(name string) {
+		err := os.Remove(name)
+		assert.NoError(t, err, "Failed to remove output temporary file")
+	}(tmpOutputFile.Name())
+
+	err = Convert(tmpFile.Name(), tmpOutputFile.Name())
+	assert.NoError(t, err, "Conversion failed")
+
+	outputData, err := os.ReadFile(tmpOutputFile.Name())
+	assert.NoError(t, err, "Failed to read output YAML file")
+
+	assert.Equal(t, expectedMessageYAML, string(outputData), "YAML output did not match expected")
+
+	err = tmpFile.Close()
+	assert.NoError(t, err)
+
+	err = tmpOutputFile.Close()
+	assert.NoError(t, err)
+}
+
+func TestConvertRESXToTOML(t *testing.T) {
+	resxContent := `<?xml version="1.0" encoding="utf-8"?>
+<root>
+  <data name="farewell" xml:space="preserve">
+    <value>Goodbye</value>
+    <comment>A farewell message</comment>
+  </data>
+  <data name="greeting" xml:space="preserve">
+    <value>Hello</value>
+    <comment>A greeting message</comment>
+  </data>
+  <data name="logo" type="System.Resources.ResXFileRef, System.Windows.Forms">
+    <value>logo.png</value>
+  </data>
+</root>`
+	tmpFile, err := os.CreateTemp("", "test_input_*.resx")
+	assert.NoError(t, err)
+
+	defer func(name string) {
+		err := os.Remove(name)
+		assert.NoError(t, err, "Failed to remove input temporary file")
+	}(tmpFile.Name())
+
+	_, err = tmpFile.WriteString(resxContent)
+	assert.NoError(t, err)
+
+	tmpOutputFile, err := os.CreateTemp("", "test_output_*.toml")
+	assert.NoError(t, err)
+	defer func(name string) {
+		err := os.Remove(name)
+		assert.NoError(t, err, "Failed to remove output temporary file")
+	}(tmpOutputFile.Name())
+
+	err = Convert(tmpFile.Name(), tmpOutputFile.Name())
+	assert.NoError(t, err, "Conversion failed")
+
+	outputData, err := os.ReadFile(tmpOutputFile.Name())
+	assert.NoError(t, err, "Failed to read output TOML file")
+
+	assert.Equal(t, expectedMessageTOML, string(outputData), "TOML output did not match expected")
+
+	err = tmpFile.Close()
+	assert.NoError(t, err)
+
+	err = tmpOutputFile.Close()
+	assert.NoError(t, err)
+}
+
+func TestConvertTSToYAML(t *testing.T) {
+	tsContent := `<?xml version="1.0" encoding="utf-8"?>
+<!DOCTYPE TS>
+<TS version="2.1" language="en">
+<context>
+    <name></name>
+    <message>
+        <source>farewell</source>
+        <extracomment>A farewell message</extracomment>
+        <translation>Goodbye</translation>
+    </message>
+    <message>
+        <source>greeting</source>
+        <extracomment>A greeting message</extracomment>
+        <translation>Hello</translation>
+    </message>
+    <message>
+        <source>unused</source>
+        <translation type="obsolete">Unused</translation>
+    </message>
+</context>
+</TS>`
+	tmpFile, err := os.CreateTemp("", "test_input_*.ts")
+	assert.NoError(t, err)
+
+	defer func(name string) {
+		err := os.Remove(name)
+		assert.NoError(t, err, "Failed to remove input temporary file")
+	}(tmpFile.Name())
+
+	_, err = tmpFile.WriteString(tsContent)
+	assert.NoError(t, err)
+
+	tmpOutputFile, err := os.CreateTemp("", "test_output_*.yaml")
+	assert.NoError(t, err)
+	defer func(name string) {
+		err := os.Remove(name)
+		assert.NoError(t, err, "Failed to remove output temporary file")
+	}(tmpOutputFile.Name())
+
+	err = Convert(tmpFile.Name(), tmpOutputFile.Name())
+	assert.NoError(t, err, "Conversion failed")
+
+	outputData, err := os.ReadFile(tmpOutputFile.Name())
+	assert.NoError(t, err, "Failed to read output YAML file")
+
+	assert.Equal(t, expectedMessageYAML, string(outputData), "YAML output did not match expected")
+
+	err = tmpFile.Close()
+	assert.NoError(t, err)
+
+	err = tmpOutputFile.Close()
+	assert.NoError(t, err)
+}
+
+func TestConvertFluentToJSON(t *testing.T) {
+	ftlContent := `## Messages
+
+# A farewell message
+farewell = Goodbye
+
+# A greeting message
+greeting =
+    Hello
+`
+	tmpFile, err := os.CreateTemp("", "test_input_*.ftl")
+	assert.NoError(t, err)
+
+	defer func(name string) {
+		err := os.Remove(name)
+		assert.NoError(t, err, "Failed to remove input temporary file")
+	}(tmpFile.Name())
+
+	_, err = tmpFile.WriteString(ftlContent)
+	assert.NoError(t, err)
+
+	tmpOutputFile, err := os.CreateTemp("", "test_output_*.json")
+	assert.NoError(t, err)
+	defer func(name string) {
+		err := os.Remove(name)
+		assert.NoError(t, err, "Failed to remove output temporary file")
+	}(tmpOutputFile.Name())
+
+	err = Convert(tmpFile.Name(), tmpOutputFile.Name())
+	assert.NoError(t, err, "Conversion failed")
+
+	outputData, err := os.ReadFile(tmpOutputFile.Name())
+	assert.NoError(t, err, "Failed to read output JSON file")
+
+	assert.JSONEq(t, expectedMessageJSON, string(outputData), "JSON output did not match expected")
+
+	err = tmpFile.Close()
+	assert.NoError(t, err)
+
+	err = tmpOutputFile.Close()
+	assert.NoError(t, err)
+}
+
+func TestConvertTOMLToTSV(t *testing.T) {
+	tmpFile, err := os.CreateTemp("", "test_input_*.toml")
+	assert.NoError(t, err)
+
+	defer func(name string) {
+		err := os.Remove(name)
+		assert.NoError(t, err, "Failed to remove input temporary file")
+	}(tmpFile.Name())
+
+	_, err = tmpFile.WriteString(expectedMessageTOML)
+	assert.NoError(t, err)
+
+	tmpOutputFile, err := os.CreateTemp("", "test_output_*.tsv")
+	assert.NoError(t, err)
+	defer func(name string) {
+		err := os.Remove(name)
+		assert.NoError(t, err, "Failed to remove output temporary file")
+	}(tmpOutputFile.Name())
+
+	err = Convert(tmpFile.Name(), tmpOutputFile.Name())
+	assert.NoError(t, err, "Conversion failed")
+
+	outputData, err := os.ReadFile(tmpOutputFile.Name())
+	assert.NoError(t, err, "Failed to read output TSV file")
+
+	expectedTSV := "\ufeffid\tdescription\tother\r\n" +
+		"farewell\tA farewell message\tGoodbye\r\n" +
+		"greeting\tA greeting message\tHello\r\n"
+	assert.Equal(t, expectedTSV, string(outputData), "TSV output did not match expected")
+
+	// Reading the spreadsheet back gives the original messages.
+	roundTripFile := tmpOutputFile.Name() + ".toml"
+	defer func(name string) {
+		err := os.Remove(name)
+		assert.NoError(t, err, "Failed to remove round trip temporary file")
+	}(roundTripFile)
+
+	err = Convert(tmpOutputFile.Name(), roundTripFile)
+	assert.NoError(t, err, "Conversion failed")
+
+	outputData, err = os.ReadFile(roundTripFile)
+	assert.NoError(t, err, "Failed to read output TOML file")
+
+	assert.Equal(t, expectedMessageTOML, string(outputData), "TOML output did not match expected")
+
+	err = tmpFile.Close()
+	assert.NoError(t, err)
+
+	err = tmpOutputFile.Close()
+	assert.NoError(t, err)
+}
+
+func TestConvertAllLocalesCSV(t *testing.T) {
+	tmpFile, err := os.CreateTemp("", "test_input_*.csv")
+	assert.NoError(t, err)
+
+	defer func(name string) {
+		err := os.Remove(name)
+		assert.NoError(t, err, "Failed to remove input temporary file")
+	}(tmpFile.Name())
+
+	_, err = tmpFile.WriteString("id,description,en,de,fr\n" +
+		"farewell,A farewell message,Goodbye,Auf Wiedersehen,\n" +
+		"greeting,A greeting message,Hello,Hallo,Bonjour\n")
+	assert.NoError(t, err)
+
+	outDir, err := os.MkdirTemp("", "test_output_*")
+	assert.NoError(t, err)
+	defer func(name string) {
+		err := os.RemoveAll(name)
+		assert.NoError(t, err, "Failed to remove output temporary directory")
+	}(outDir)
+
+	written, err := ConvertAllLocales(tmpFile.Name(), filepath.Join(outDir, "active.{locale}.toml"), Options{})
+	assert.NoError(t, err, "Conversion failed")
+	assert.Equal(t, []string{
+		filepath.Join(outDir, "active.en.toml"),
+		filepath.Join(outDir, "active.de.toml"),
+		filepath.Join(outDir, "active.fr.toml"),
+	}, written)
+
+	outputData, err := os.ReadFile(written[0])
+	assert.NoError(t, err, "Failed to read output TOML file")
+	assert.Equal(t, expectedMessageTOML, string(outputData), "TOML output did not match expected")
+
+	outputData, err = os.ReadFile(written[2])
+	assert.NoError(t, err, "Failed to read output TOML file")
+	assert.Equal(t, "[greeting]\ndescription = \"A greeting message\"\nother = \"Bonjour\"\n\n", string(outputData), "TOML output did not match expected")
+
+	err = tmpFile.Close()
+	assert.NoError(t, err)
+}
+
+func TestConvertXLSXToYAML(t *testing.T) {
+	tmpFile, err := os.CreateTemp("", "test_input_*.xlsx")
+	assert.NoError(t, err)
+
+	defer func(name string) {
+		err := os.Remove(name)
+		assert.NoError(t, err, "Failed to remove input temporary file")
+	}(tmpFile.Name())
+
+	cell := func(reference string, text string) string {
+		return `<c r="` + reference + `" t="inlineStr"><is><t>` + text + `</t></is></c>`
+	}
+	parts := map[string]string{
+		"xl/workbook.xml": `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+			`<sheets><sheet name="Sheet1" sheetId="1" r:id="rId1"/></sheets></workbook>`,
+		"xl/_rels/workbook.xml.rels": `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Target="worksheets/sheet1.xml"/></Relationships>`,
+		"xl/worksheets/sheet1.xml": `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>` +
+			`<row r="1">` + cell("A1", "id") + cell("B1", "description") + cell("C1", "other") + `</row>` +
+			`<row r="2">` + cell("A2", "greeting") + cell("B2", "A greeting message") + cell("C2", "Hello") + `</row>` +
+			`<row r="3">` + cell("A3", "farewell") + cell("B3", "A farewell message") + cell("C3", "Goodbye") + `</row>` +
+			`</sheetData></worksheet>`,
+	}
+	archive := zip.NewWriter(tmpFile)
+	for name, content := range parts {
+		writer, err := archive.Create(name)
+		assert.NoError(t, err)
+		_, err = writer.Write([]byte(content))
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, archive.Close())
+
+	tmpOutputFile, err := os.CreateTemp("", "test_output_*.yaml")
+	assert.NoError(t, err)
+	defer func(name string) {
+		err := os.Remove(name)
+		assert.NoError(t, err, "Failed to remove output temporary file")
+	}(tmpOutputFile.Name())
+
+	err = Convert(tmpFile.Name(), tmpOutputFile.Name())
+	assert.NoError(t, err, "Conversion failed")
+
+	outputData, err := os.ReadFile(tmpOutputFile.Name())
+	assert.NoError(t, err, "Failed to read output YAML file")
+
+	assert.Equal(t, expectedMessageYAML, string(outputData), "YAML output did not match expected")
+
+	err = tmpFile.Close()
+	assert.NoError(t, err)
+
+	err = tmpOutputFile.Close()
+	assert.NoError(t, err)
+}
+
+func TestConvertTOMLToProperties(t *testing.T) {
+	// Write TOML content to a temporary file
+	tmpFile, err := os.CreateTemp("", "test_input_*.toml")
+	assert.NoError(t, err)
+
+	defer func(name string) {
+		err := os.Remove(name)
+		assert.NoError(t, err, "Failed to remove input temporary file")
+	}(tmpFile.Name())
+
+	_, err = tmpFile.WriteString("[farewell]\nother = \"Tschüss\"\n\n[greeting]\nother = \"Grüß dich: 😀\"\n")
+	assert.NoError(t, err)
+
+	tmpOutputFile, err := os.CreateTemp("", "test_output_*.properties")
+	assert.NoError(t, err)
+	defer func(name string) {
+		err := os.Remove(name)
+		assert.NoError(t, err, "Failed to remove output temporary file")
+	}(tmpOutputFile.Name())
+
+	opts := Options{Properties: parser.PropertiesOptions{Encoding: parser.PropertiesEncodingISO88591}}
+	err = ConvertWithOptions(tmpFile.Name(), tmpOutputFile.Name(), opts)
+	assert.NoError(t, err, "Conversion failed")
+
+	outputData, err := os.ReadFile(tmpOutputFile.Name())
+	assert.NoError(t, err, "Failed to read output properties file")
+
+	expected := "farewell=Tsch\\u00FCss\ngreeting=Gr\\u00FC\\u00DF dich\\: \\uD83D\\uDE00\n"
+	assert.Equal(t, expected, string(outputData), "Properties output did not match expected")
+
+	err = tmpFile.Close()
+	assert.NoError(t, err)
+
+	err = tmpOutputFile.Close()
+	assert.NoError(t, err)
+}
+
+func TestConvertPropertiesXMLToYAML(t *testing.T) {
+	// Write Java XML properties content to a temporary file
+	tmpFile, err := os.CreateTemp("", "test_input_*.xml")
+	assert.NoError(t, err)
+
+	defer func(name string) {
+		err := os.Remove(name)
+		assert.NoError(t, err, "Failed to remove input temporary file")
+	}(tmpFile.Name())
+
+	_, err = tmpFile.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="no"?>
+<!DOCTYPE properties SYSTEM "http://java.sun.com/dtd/properties.dtd">
+<properties>
+<!-- A greeting message -->
+<entry key="greeting">Hello</entry>
+<!-- A farewell message -->
+<entry key="farewell">Goodbye</entry>
+</properties>
+`)
+	assert.NoError(t, err)
+
+	tmpOutputFile, err := os.CreateTemp("", "test_output_*.yaml")
+	assert.NoError(t, err)
+	defer func(name string) {
+		err := os.Remove(name)
+		assert.NoError(t, err, "Failed to remove output temporary file")
+	}(tmpOutputFile.Name())
+
+	err = Convert(tmpFile.Name(), tmpOutputFile.Name())
+	assert.NoError(t, err, "Conversion failed")
+
+	outputData, err := os.ReadFile(tmpOutputFile.Name())
+	assert.NoError(t, err, "Failed to read output YAML file")
+
+	assert.Equal(t, expectedMessageYAML, string(outputData), "YAML output did not match expected")
+
+	err = tmpFile.Close()
+	assert.NoError(t, err)
+
+	err = tmpOutputFile.Close()
+	assert.NoError(t, err)
+}
+
+func TestConvertJSONToNestedYAML(t *testing.T) {
+	// Write nested JSON content to a temporary file
+	tmpFile, err := os.CreateTemp("", "test_input_*.json")
+	assert.NoError(t, err)
+
+	defer func(name string) {
+		err := os.Remove(name)
+		assert.NoError(t, err, "Failed to remove input temporary file")
+	}(tmpFile.Name())
+
+	_, err = tmpFile.WriteString(`{"menu": {"title": "Menu", "items": ["Open", "Close"]}, "cart": {"one": "{{.Count}} item", "other": "{{.Count}} items"}}`)
+	assert.NoError(t, err)
+
+	tmpOutputFile, err := os.CreateTemp("", "test_output_*.yaml")
+	assert.NoError(t, err)
+	defer func(name string) {
+		err := os.Remove(name)
+		assert.NoError(t, err, "Failed to remove output temporary file")
+	}(tmpOutputFile.Name())
+
+	err = ConvertWithOptions(tmpFile.Name(), tmpOutputFile.Name(), Options{Unflatten: true})
+	assert.NoError(t, err, "Conversion failed")
+
+	outputData, err := os.ReadFile(tmpOutputFile.Name())
+	assert.NoError(t, err, "Failed to read output YAML file")
+
+	expected := `cart:
+  one: '{{.Count}} item'
+  other: '{{.Count}} items'
+menu:
+  items:
+    - Open
+    - Close
+  title: Menu
+`
+	assert.Equal(t, expected, string(outputData), "YAML output did not match expected")
+
 	err = tmpFile.Close()
 	assert.NoError(t, err)
 
@@ -5,8 +5,10 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/s-nix/mk2i18n/converter"
+	"github.com/s-nix/mk2i18n/parser"
 )
 
 var SupportedInputFormats = []string{
@@ -16,6 +18,21 @@ var SupportedInputFormats = []string{
 	".yml",
 	".xml",
 	".properties",
+	".po",
+	".pot",
+	".mo",
+	".xlf",
+	".xliff",
+	".strings",
+	".stringsdict",
+	".xcstrings",
+	".arb",
+	".resx",
+	".ts",
+	".ftl",
+	".csv",
+	".tsv",
+	".xlsx",
 }
 
 var SupportedOutputFormats = []string{
@@ -23,16 +40,87 @@ var SupportedOutputFormats = []string{
 	".toml",
 	".yaml",
 	".yml",
+	".po",
+	".xlf",
+	".xliff",
+	".xml",
+	".strings",
+	".stringsdict",
+	".xcstrings",
+	".arb",
+	".resx",
+	".ts",
+	".csv",
+	".tsv",
+	".properties",
 }
 
 func main() {
 	var (
-		inFile  string
-		outFile string
+		inFile       string
+		outFile      string
+		noPlurals    bool
+		noMessages   bool
+		unflatten    bool
+		poFuzzy      bool
+		poContext    bool
+		locale       string
+		sourceLocale string
+		xliffVersion string
+		xliffSource  bool
+		xmlFormat    string
+		androidRes   string
+		androidNames string
+		xcReview     bool
+		allLocales   bool
+		jsonFormat   string
+		i18nextCtx   string
+		tsUnfinished bool
+		csvColumns   string
+		xlsxSheet    string
+		propComments string
+		propNoHeader bool
+		propEncoding string
 	)
-	flag.StringVar(&inFile, "i", "", "Input file path. Supported formats are .json, .toml, .yaml, .yml, .xml, and .properties")
-	flag.StringVar(&outFile, "p", "", "Output file path. Supported formats are .json, .toml, .yaml.")
+	flag.StringVar(&inFile, "i", "", "Input file path. Supported formats are .json, .toml, .yaml, .yml, .xml, .properties, .po, .pot, .mo, .xlf, .xliff, .strings, .stringsdict, .xcstrings, .arb, .resx, .ts, .ftl, .csv, .tsv, and .xlsx")
+	flag.StringVar(&outFile, "p", "", "Output file path. Supported formats are .json, .toml, .yaml, .po, .xlf, .xliff, .xml (Android string resources), .strings, .stringsdict, .xcstrings, .arb, .resx, .ts, .csv, .tsv, and .properties. With -all-locales, a template containing {locale}.")
+	flag.StringVar(&locale, "locale", "", "Locale of the output file, such as de or pt_BR. Selects the plural forms written to .po files, the XLIFF target language, the .xcstrings locale, the ARB @@locale, the .ts language and the .csv locale columns.")
+	flag.StringVar(&sourceLocale, "source-locale", "en", "Source language written to XLIFF output and to new .xcstrings catalogs.")
+	flag.BoolVar(&noPlurals, "no-plurals", false, "Keep plural sub-keys (items.one, items.other) as separate messages instead of grouping them into one plural message.")
+	flag.BoolVar(&noMessages, "no-messages", false, "Keep go-i18n message objects (greeting: {description, other}) as separate messages instead of reading them as one message.")
+	flag.BoolVar(&unflatten, "unflatten", false, "Write .json, .toml and .yaml output as plain nested data rebuilt from the message IDs (menu.items.0 becomes menu: {items: [...]}) instead of go-i18n message objects.")
+	flag.BoolVar(&poFuzzy, "po-fuzzy", false, "Use the translations of PO entries flagged as fuzzy instead of treating them as untranslated.")
+	flag.BoolVar(&poContext, "po-context", false, "Write message IDs as msgctxt and the message text as msgid in .po output, and read the msgctxt alone as the message ID of .po and .mo input.")
+	flag.StringVar(&xliffVersion, "xliff-version", "1.2", "XLIFF version to write, 1.2 or 2.0.")
+	flag.BoolVar(&xliffSource, "xliff-source", false, "Read XLIFF source elements instead of targets, and write XLIFF without targets.")
+	flag.StringVar(&xmlFormat, "xml-format", "", "How .xml input is read: generic, android or properties (Java XML properties). Detected from the file content when empty.")
+	flag.StringVar(&androidRes, "android-res", "", "Android res directory to write to instead of -p. The output goes to values-<locale>/strings.xml inside it.")
+	flag.StringVar(&androidNames, "android-names", "", "Comma separated message ID to Android resource name mapping, such as home.title=home_title,app.name=app_name.")
+	flag.BoolVar(&xcReview, "xcstrings-review", false, "Use string catalog translations in the needs_review state instead of treating them as untranslated.")
+	flag.BoolVar(&allLocales, "all-locales", false, "Convert every locale of a multi-locale input, such as a .xcstrings catalog or a .csv or .xlsx file with locale columns, into its own file. -p must contain {locale}.")
+	flag.StringVar(&jsonFormat, "json-format", "", "How .json files are read and written: generic, i18next or chrome. When empty, Chrome extension messages are detected from the input content and output is generic.")
+	flag.StringVar(&i18nextCtx, "i18next-context", "#", "Separator between a message ID and its i18next context, so that friend_male becomes friend#male.")
+	flag.BoolVar(&tsUnfinished, "ts-unfinished", false, "Use the translations of .ts messages marked unfinished instead of treating them as untranslated.")
+	flag.StringVar(&csvColumns, "csv-columns", "", "Comma separated column layout of .csv, .tsv and .xlsx files, such as id,description,one,other or id,en,de. Taken from the header row when empty.")
+	flag.StringVar(&xlsxSheet, "xlsx-sheet", "", "Name of the sheet read from .xlsx workbooks. Defaults to the first sheet.")
+	flag.StringVar(&propComments, "properties-comments", "block", "Which comment lines above a .properties key become its description: block (the comment block directly above), nearest (the last comment line only) or all (every comment since the previous key).")
+	flag.BoolVar(&propNoHeader, "properties-ignore-header", false, "Ignore the comment block at the top of .properties files, such as a license, when a blank line follows it.")
+	flag.StringVar(&propEncoding, "properties-encoding", "", "Encoding of .properties files: utf-8 or iso-8859-1. When empty, UTF-8 input is detected and other input is read as ISO-8859-1, and output is UTF-8. iso-8859-1 output escapes every non-ASCII character as \\uXXXX.")
 	flag.Parse()
+	if androidRes != "" {
+		outFile = parser.AndroidResourcePath(androidRes, locale)
+	}
+	nameMapping := map[string]string{}
+	for _, pair := range strings.Split(androidNames, ",") {
+		id, name, found := strings.Cut(pair, "=")
+		if found {
+			nameMapping[strings.TrimSpace(id)] = strings.TrimSpace(name)
+		}
+	}
+	var columns []string
+	if csvColumns != "" {
+		columns = strings.Split(csvColumns, ",")
+	}
 	outPath, outFileName := filepath.Split(outFile)
 
 	if outPath == "" {
@@ -94,8 +182,9 @@ func main() {
 	}
 
 	// If the output path is specified, but it is a file, exit with an error
+	// String catalogs are the exception, messages are merged into them.
 	outFileInfo, err := os.Stat(outFile)
-	if err == nil && !outFileInfo.IsDir() {
+	if err == nil && !outFileInfo.IsDir() && outType != ".xcstrings" {
 		_, err := fmt.Fprintf(os.Stderr, "Output path is a file, not a directory: %s\n", outFile)
 		if err != nil {
 			os.Exit(1)
@@ -104,8 +193,9 @@ func main() {
 	}
 
 	// Ensure the output path exists. If not, create it.
+	// With -all-locales the converter creates the directories of each locale.
 	_, err = os.Stat(outPath)
-	if os.IsNotExist(err) {
+	if os.IsNotExist(err) && !allLocales {
 		err := os.MkdirAll(outPath, os.ModePerm)
 		if err != nil {
 			_, err := fmt.Fprintf(os.Stderr, "Failed to create output directory: %s\n", outPath)
@@ -131,7 +221,61 @@ func main() {
 		os.Exit(2)
 	}
 
-	err = converter.Convert(inFile, outFile)
+	opts := converter.Options{
+		Flatten: parser.FlattenOptions{
+			DisablePluralDetection:  noPlurals,
+			DisableMessageDetection: noMessages,
+		},
+		Unflatten: unflatten,
+		Properties: parser.PropertiesOptions{
+			Comments:     parser.PropertiesComments(propComments),
+			IgnoreHeader: propNoHeader,
+			Encoding:     parser.PropertiesEncoding(propEncoding),
+
+			DisablePluralDetection: noPlurals,
+		},
+		PO: parser.POOptions{
+			IncludeFuzzy: poFuzzy,
+			Locale:       locale,
+			IDAsContext:  poContext,
+		},
+		XLIFF: parser.XLIFFOptions{
+			UseSource:      xliffSource,
+			Version:        xliffVersion,
+			SourceLanguage: sourceLocale,
+			TargetLanguage: locale,
+		},
+		Android: parser.AndroidOptions{
+			NameMapping: nameMapping,
+		},
+		XCStrings: parser.XCStringsOptions{
+			Locale:             locale,
+			SourceLanguage:     sourceLocale,
+			IncludeNeedsReview: xcReview,
+		},
+		ARB: parser.ARBOptions{
+			Locale: locale,
+		},
+		TS: parser.TSOptions{
+			IncludeUnfinished: tsUnfinished,
+			Locale:            locale,
+		},
+		CSV: parser.CSVOptions{
+			Columns: columns,
+			Locale:  locale,
+			Sheet:   xlsxSheet,
+		},
+		I18next: parser.I18nextOptions{
+			ContextSeparator: i18nextCtx,
+		},
+		JSONFormat: parser.JSONFormat(jsonFormat),
+		XMLFormat:  parser.XMLFormat(xmlFormat),
+	}
+	if allLocales {
+		_, err = converter.ConvertAllLocales(inFile, outFile, opts)
+	} else {
+		err = converter.ConvertWithOptions(inFile, outFile, opts)
+	}
 	if err != nil {
 		_, err := fmt.Fprintf(os.Stderr, "Conversion failed: %v\n", err)
 		if err != nil {
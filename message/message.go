@@ -8,27 +8,120 @@ import (
 	"gopkg.in/yaml.v3"
 )
 
-// Message represents a localization message with an ID, Description, and Other string as specified in ICU MessageFormat.
+// PluralCategories lists the CLDR plural categories in their canonical order.
+var PluralCategories = []string{"zero", "one", "two", "few", "many", "other"}
+
+// Message represents a localization message with an ID, Description, and the plural forms
+// of its translation as specified in ICU MessageFormat and used by go-i18n.
 type Message struct {
 	// ID is the identifier for the message.
 	// It is used as the key in the output formats.
 	ID string
 
+	// Hash uniquely identifies the content of the message that this message was translated from.
+	Hash string
+
 	// Description provides additional context about the message.
 	Description string
 
+	// LeftDelim is the left Go template delimiter.
+	LeftDelim string
+
+	// RightDelim is the right Go template delimiter.
+	RightDelim string
+
+	// Zero is the content of the message for the CLDR plural form "zero".
+	Zero string
+
+	// One is the content of the message for the CLDR plural form "one".
+	One string
+
+	// Two is the content of the message for the CLDR plural form "two".
+	Two string
+
+	// Few is the content of the message for the CLDR plural form "few".
+	Few string
+
+	// Many is the content of the message for the CLDR plural form "many".
+	Many string
+
 	// Other contains the actual message string in ICU MessageFormat.
+	// It is also the content of the message for the CLDR plural form "other".
 	Other string
 }
 
+// PluralForm returns the content of the message for the given CLDR plural category.
+// An unknown category yields an empty string.
+func (m *Message) PluralForm(category string) string {
+	switch category {
+	case "zero":
+		return m.Zero
+	case "one":
+		return m.One
+	case "two":
+		return m.Two
+	case "few":
+		return m.Few
+	case "many":
+		return m.Many
+	case "other":
+		return m.Other
+	}
+	return ""
+}
+
+// SetPluralForm sets the content of the message for the given CLDR plural category.
+// It reports whether the category was recognized.
+func (m *Message) SetPluralForm(category string, value string) bool {
+	switch category {
+	case "zero":
+		m.Zero = value
+	case "one":
+		m.One = value
+	case "two":
+		m.Two = value
+	case "few":
+		m.Few = value
+	case "many":
+		m.Many = value
+	case "other":
+		m.Other = value
+	default:
+		return false
+	}
+	return true
+}
+
+// IsPlural reports whether the message carries any plural form besides Other.
+func (m *Message) IsPlural() bool {
+	return m.Zero != "" || m.One != "" || m.Two != "" || m.Few != "" || m.Many != ""
+}
+
 // BuildMap builds a map representation of the Message.
+// The description and other fields are always present, the remaining go-i18n fields only when they are not empty.
 func (m *Message) BuildMap() map[string]interface{} {
 	propName := m.ID
 	result := map[string]interface{}{}
-	result[propName] = map[string]string{
+	fields := map[string]string{
 		"description": m.Description,
 		"other":       m.Other,
 	}
+	optional := map[string]string{
+		"hash":       m.Hash,
+		"leftDelim":  m.LeftDelim,
+		"rightDelim": m.RightDelim,
+		"zero":       m.Zero,
+		"one":        m.One,
+		"two":        m.Two,
+		"few":        m.Few,
+		"many":       m.Many,
+	}
+	for key, value := range optional {
+		if value != "" {
+			fields[key] = value
+		}
+	}
+	result[propName] = fields
 	return result
 }
 
@@ -190,3 +190,98 @@ func TestMessage_MarshalYAML_EmptyMessage(t *testing.T) {
 `
 	assert.Equal(t, expectedYAML, string(yamlData.([]byte)), "YAML output did not match expected for empty message")
 }
+
+func TestMessage_MarshalJSON_PluralForms(t *testing.T) {
+	msg := &Message{
+		ID:          "items",
+		Description: "Number of items",
+		Zero:        "No items",
+		One:         "{{.Count}} item",
+		Other:       "{{.Count}} items",
+	}
+
+	jsonData, err := msg.MarshalJSON()
+	assert.NoError(t, err, "Expected no error during JSON marshaling")
+
+	expectedJSON := `{"items":{"description":"Number of items","zero":"No items","one":"{{.Count}} item","other":"{{.Count}} items"}}`
+	assert.JSONEq(t, expectedJSON, string(jsonData), "JSON output did not match expected for plural forms")
+}
+
+func TestMessage_MarshalJSON_AllFields(t *testing.T) {
+	msg := &Message{
+		ID:          "cats",
+		Hash:        "sha1-123",
+		Description: "Cats",
+		LeftDelim:   "<<",
+		RightDelim:  ">>",
+		Zero:        "zero",
+		One:         "one",
+		Two:         "two",
+		Few:         "few",
+		Many:        "many",
+		Other:       "other",
+	}
+
+	jsonData, err := msg.MarshalJSON()
+	assert.NoError(t, err, "Expected no error during JSON marshaling")
+
+	expectedJSON := `{"cats":{"hash":"sha1-123","description":"Cats","leftDelim":"<<","rightDelim":">>","zero":"zero","one":"one","two":"two","few":"few","many":"many","other":"other"}}`
+	assert.JSONEq(t, expectedJSON, string(jsonData), "JSON output did not match expected for all fields")
+}
+
+func TestMessage_MarshalTOML_PluralForms(t *testing.T) {
+	msg := &Message{
+		ID:    "items",
+		One:   "{{.Count}} item",
+		Few:   "{{.Count}} itemy",
+		Other: "{{.Count}} items",
+	}
+
+	tomlData, err := msg.MarshalTOML()
+	assert.NoError(t, err, "Expected no error during TOML marshaling")
+
+	expectedTOML :=
+		`[items]
+description = ""
+few = "{{.Count}} itemy"
+one = "{{.Count}} item"
+other = "{{.Count}} items"
+`
+	assert.Equal(t, expectedTOML, string(tomlData), "TOML output did not match expected for plural forms")
+}
+
+func TestMessage_MarshalYAML_PluralForms(t *testing.T) {
+	msg := &Message{
+		ID:    "items",
+		Hash:  "sha1-abc",
+		One:   "one item",
+		Other: "many items",
+	}
+
+	yamlData, err := msg.MarshalYAML()
+	assert.NoError(t, err, "Expected no error during YAML marshaling")
+
+	expectedYAML :=
+		`items:
+  description: ""
+  hash: sha1-abc
+  one: one item
+  other: many items
+`
+	assert.Equal(t, expectedYAML, string(yamlData.([]byte)), "YAML output did not match expected for plural forms")
+}
+
+func TestMessage_PluralForm(t *testing.T) {
+	msg := &Message{ID: "items"}
+	for _, category := range PluralCategories {
+		assert.True(t, msg.SetPluralForm(category, category+" form"))
+	}
+	assert.False(t, msg.SetPluralForm("several", "unknown"))
+
+	for _, category := range PluralCategories {
+		assert.Equal(t, category+" form", msg.PluralForm(category))
+	}
+	assert.Equal(t, "", msg.PluralForm("several"))
+	assert.True(t, msg.IsPlural())
+	assert.False(t, (&Message{Other: "single"}).IsPlural())
+}
@@ -0,0 +1,422 @@
+package parser
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/s-nix/mk2i18n/message"
+)
+
+// androidItem is an item of a string-array or plurals resource.
+type androidItem struct {
+	quantity string
+	text     string
+}
+
+// AndroidOptions configures how Android string resources are written.
+type AndroidOptions struct {
+	// NameMapping maps message IDs to resource names. IDs that are not in the mapping are turned into
+	// valid resource names by replacing every character other than letters, digits and underscores
+	// with an underscore, so that home.title becomes home_title.
+	NameMapping map[string]string
+}
+
+var (
+	// rAndroidInvalidName matches the characters that are not allowed in Android resource names.
+	rAndroidInvalidName = regexp.MustCompile(`[^A-Za-z0-9_]`)
+
+	// rAndroidArrayItem matches the IDs FromAndroidXML gives to string-array items, such as planets.0.
+	rAndroidArrayItem = regexp.MustCompile(`^(.+)\.(\d+)$`)
+)
+
+// AndroidResourcePath returns the path of the strings.xml file for a locale inside an Android res directory.
+// The locale en_GB, or en-GB, becomes the resource qualifier values-en-rGB; an empty locale gives the default values directory.
+func AndroidResourcePath(resDir string, locale string) string {
+	directory := "values"
+	if locale != "" {
+		language, region, found := strings.Cut(strings.ReplaceAll(locale, "_", "-"), "-")
+		directory += "-" + strings.ToLower(language)
+		if found {
+			directory += "-r" + strings.ToUpper(region)
+		}
+	}
+	return filepath.Join(resDir, directory, "strings.xml")
+}
+
+// ToAndroidXML converts a slice of message.Message objects into an Android string resource file with the default options.
+func ToAndroidXML(messages []message.Message) (string, error) {
+	return ToAndroidXMLWithOptions(messages, AndroidOptions{})
+}
+
+// ToAndroidXMLWithOptions converts a slice of message.Message objects into an Android string resource file.
+//
+// Plural messages become plurals, messages with indexed IDs from 0 up (planets.0, planets.1) become
+// a string-array, and every other message becomes a string. Descriptions are written as comments.
+// Values are escaped for Android, including apostrophes, quotes and at-signs, and template fields
+// such as {{.Arg1}} become printf placeholders such as %1$s, or %1$d for {{printf "%d" .Arg1}}.
+func ToAndroidXMLWithOptions(messages []message.Message, opts AndroidOptions) (string, error) {
+	// arrays holds the items of each string-array, indexed by the base ID.
+	arrays := map[string][]*message.Message{}
+	for i := range messages {
+		msg := &messages[i]
+		match := rAndroidArrayItem.FindStringSubmatch(msg.ID)
+		if match == nil || msg.IsPlural() {
+			continue
+		}
+		index, _ := strconv.Atoi(match[2])
+		items := arrays[match[1]]
+		for len(items) <= index {
+			items = append(items, nil)
+		}
+		items[index] = msg
+		arrays[match[1]] = items
+	}
+	for base, items := range arrays {
+		for _, item := range items {
+			if item == nil {
+				// Indexes with gaps are not an array, the items are written as strings.
+				delete(arrays, base)
+				break
+			}
+		}
+	}
+
+	var sb strings.Builder
+	sb.WriteString(xml.Header)
+	sb.WriteString("<resources>\n")
+	names := map[string]string{}
+	writeName := func(id string) (string, error) {
+		name, ok := opts.NameMapping[id]
+		if !ok {
+			name = rAndroidInvalidName.ReplaceAllString(id, "_")
+			if name == "" || name[0] >= '0' && name[0] <= '9' {
+				name = "_" + name
+			}
+		}
+		if other, exists := names[name]; exists {
+			return "", fmt.Errorf("message IDs %q and %q both map to Android resource name %q", other, id, name)
+		}
+		names[name] = id
+		return name, nil
+	}
+	writeComment := func(description string) {
+		if description != "" {
+			sb.WriteString("    <!-- " + strings.ReplaceAll(description, "--", "- -") + " -->\n")
+		}
+	}
+
+	for _, msg := range messages {
+		if match := rAndroidArrayItem.FindStringSubmatch(msg.ID); match != nil && arrays[match[1]] != nil {
+			if match[2] != "0" {
+				continue
+			}
+			items := arrays[match[1]]
+			name, err := writeName(match[1])
+			if err != nil {
+				return "", err
+			}
+			writeComment(items[0].Description)
+			values := make([]string, len(items))
+			for i, item := range items {
+				values[i] = item.Other
+			}
+			sb.WriteString(`    <string-array name="` + name + `"` + androidUnformatted(values...) + ">\n")
+			for _, item := range items {
+				sb.WriteString("        <item>" + androidEscape(item.Other) + "</item>\n")
+			}
+			sb.WriteString("    </string-array>\n")
+			continue
+		}
+
+		name, err := writeName(msg.ID)
+		if err != nil {
+			return "", err
+		}
+		writeComment(msg.Description)
+		if !msg.IsPlural() {
+			sb.WriteString(`    <string name="` + name + `"` + androidUnformatted(msg.Other) + ">" + androidEscape(msg.Other) + "</string>\n")
+			continue
+		}
+		var forms []string
+		for _, category := range message.PluralCategories {
+			forms = append(forms, msg.PluralForm(category))
+		}
+		sb.WriteString(`    <plurals name="` + name + `"` + androidUnformatted(forms...) + ">\n")
+		for _, category := range message.PluralCategories {
+			if form := msg.PluralForm(category); form != "" {
+				sb.WriteString(`        <item quantity="` + category + `">` + androidEscape(form) + "</item>\n")
+			}
+		}
+		sb.WriteString("    </plurals>\n")
+	}
+	sb.WriteString("</resources>\n")
+	return sb.String(), nil
+}
+
+// FromAndroidXML reads an Android string resource file, such as res/values/strings.xml, into messages.
+//
+// The name attribute is the message ID. A string-array becomes one message per item with
+// indexed IDs (planets.0, planets.1), and plurals become a single message whose item quantities
+// are the plural forms. Resources marked translatable="false" are skipped. A comment right before a
+// resource becomes its Description. Android escapes and quoting are resolved, and printf placeholders
+// such as %1$s and %1$d are converted into template fields such as {{.Arg1}} and {{printf "%d" .Arg1}}, except in resources marked formatted="false".
+func FromAndroidXML(inputPath string) ([]message.Message, error) {
+	content, err := os.ReadFile(inputPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var messages []message.Message
+	decoder := xml.NewDecoder(bytes.NewReader(content))
+	depth := 0
+	comment := ""
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch tt := token.(type) {
+		case xml.Comment:
+			if depth == 1 {
+				comment = strings.TrimSpace(string(tt))
+			}
+			continue
+		case xml.EndElement:
+			depth--
+			continue
+		case xml.StartElement:
+			if depth == 0 {
+				depth++
+				continue
+			}
+		default:
+			continue
+		}
+
+		start := token.(xml.StartElement)
+		description := comment
+		comment = ""
+		name := xmlAttr(start, "name")
+		formatted := xmlAttr(start, "formatted") != "false"
+		if name == "" || xmlAttr(start, "translatable") == "false" {
+			if err := decoder.Skip(); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		switch start.Name.Local {
+		case "string":
+			var text xmlText
+			if err := decoder.DecodeElement(&text, &start); err != nil {
+				return nil, err
+			}
+			messages = append(messages, message.Message{
+				ID:          name,
+				Description: description,
+				Other:       androidValue(string(text), formatted),
+			})
+		case "string-array":
+			items, err := decodeAndroidItems(decoder)
+			if err != nil {
+				return nil, err
+			}
+			for i, item := range items {
+				messages = append(messages, message.Message{
+					ID:          fmt.Sprintf("%s.%d", name, i),
+					Description: description,
+					Other:       androidValue(item.text, formatted),
+				})
+			}
+		case "plurals":
+			items, err := decodeAndroidItems(decoder)
+			if err != nil {
+				return nil, err
+			}
+			msg := message.Message{ID: name, Description: description}
+			for _, item := range items {
+				if !msg.SetPluralForm(item.quantity, androidValue(item.text, formatted)) {
+					return nil, fmt.Errorf("%s: unknown plural quantity %q in plurals %q", inputPath, item.quantity, name)
+				}
+			}
+			messages = append(messages, msg)
+		default:
+			if err := decoder.Skip(); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if len(messages) == 0 {
+		return nil, nil
+	}
+	sort.Slice(messages, func(i, j int) bool {
+		return messages[i].ID < messages[j].ID
+	})
+	return messages, nil
+}
+
+// decodeAndroidItems reads the item children of the element the decoder is in, up to its end element.
+func decodeAndroidItems(decoder *xml.Decoder) ([]androidItem, error) {
+	var items []androidItem
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+		switch tt := token.(type) {
+		case xml.StartElement:
+			if tt.Name.Local != "item" {
+				if err := decoder.Skip(); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			var text xmlText
+			if err := decoder.DecodeElement(&text, &tt); err != nil {
+				return nil, err
+			}
+			items = append(items, androidItem{quantity: xmlAttr(tt, "quantity"), text: string(text)})
+		case xml.EndElement:
+			return items, nil
+		}
+	}
+}
+
+// xmlAttr returns the value of the attribute with the given local name, or an empty string.
+func xmlAttr(start xml.StartElement, name string) string {
+	for _, attr := range start.Attr {
+		if attr.Name.Local == name {
+			return attr.Value
+		}
+	}
+	return ""
+}
+
+// androidValue resolves the quoting and escaping of an Android string resource value
+// and, unless the resource is marked formatted="false", converts its printf placeholders into template fields.
+// Outside of double quotes, runs of whitespace collapse into a single space and surrounding whitespace is removed.
+func androidValue(raw string, formatted bool) string {
+	runes := []rune(strings.TrimSpace(raw))
+	var sb strings.Builder
+	quoted := false
+	space := false
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		if !quoted && unicode.IsSpace(r) {
+			space = true
+			continue
+		}
+		if space {
+			sb.WriteByte(' ')
+			space = false
+		}
+		switch r {
+		case '"':
+			quoted = !quoted
+		case '\\':
+			if i+1 >= len(runes) {
+				sb.WriteRune(r)
+				continue
+			}
+			i++
+			switch runes[i] {
+			case 'n':
+				sb.WriteByte('\n')
+			case 't':
+				sb.WriteByte('\t')
+			case 'u':
+				if i+4 < len(runes) {
+					if code, err := strconv.ParseUint(string(runes[i+1:i+5]), 16, 32); err == nil {
+						sb.WriteRune(rune(code))
+						i += 4
+						continue
+					}
+				}
+				sb.WriteString(`\u`)
+			default:
+				sb.WriteRune(runes[i])
+			}
+		default:
+			sb.WriteRune(r)
+		}
+	}
+	if !formatted {
+		return sb.String()
+	}
+	return printfToTemplate(sb.String())
+}
+
+// androidUnformatted returns the formatted="false" attribute, with a leading space, when none of the values
+// of a resource has template fields but some would be read back as printf placeholders, such as a literal %d.
+func androidUnformatted(values ...string) string {
+	unformatted := false
+	for _, value := range values {
+		if rTemplateArg.MatchString(value) {
+			return ""
+		}
+		if printfToTemplate(value) != value {
+			unformatted = true
+		}
+	}
+	if unformatted {
+		return ` formatted="false"`
+	}
+	return ""
+}
+
+// androidEscape converts template fields into printf placeholders and escapes a value for an Android string resource.
+// A % is only doubled in values with template fields, as values without arguments are not formatted.
+// Values with leading, trailing or repeated whitespace are wrapped in double quotes so that Android keeps the whitespace.
+func androidEscape(value string) string {
+	value = templateToPrintf(value, "s")
+	var sb strings.Builder
+	for _, r := range value {
+		switch r {
+		case '\\':
+			sb.WriteString(`\\`)
+		case '\'':
+			sb.WriteString(`\'`)
+		case '"':
+			sb.WriteString(`\"`)
+		case '@':
+			sb.WriteString(`\@`)
+		case '?':
+			if sb.Len() == 0 {
+				sb.WriteString(`\?`)
+			} else {
+				sb.WriteRune(r)
+			}
+		case '\n':
+			sb.WriteString(`\n`)
+		case '\t':
+			sb.WriteString(`\t`)
+		case '&':
+			sb.WriteString("&amp;")
+		case '<':
+			sb.WriteString("&lt;")
+		case '>':
+			sb.WriteString("&gt;")
+		default:
+			sb.WriteRune(r)
+		}
+	}
+	escaped := sb.String()
+	if strings.TrimSpace(value) != value || strings.Contains(value, "  ") {
+		escaped = `"` + escaped + `"`
+	}
+	return escaped
+}
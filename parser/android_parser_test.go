@@ -0,0 +1,145 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/s-nix/mk2i18n/message"
+	"github.com/stretchr/testify/assert"
+)
+
+const androidContent = `<?xml version="1.0" encoding="utf-8"?>
+<resources xmlns:tools="http://schemas.android.com/tools">
+    <string name="app_name" translatable="false">My App</string>
+    <!-- Shown on the start screen -->
+    <string name="welcome">Welcome, %1$s! You have %2$d new messages.</string>
+    <string name="apostrophe">Don\'t panic\nKeep   calm</string>
+    <string name="quoted">"  spaced   out  "</string>
+    <string name="escapes">\@home \?mark 100%% é</string>
+    <string name="farewell">Voilà à bientôt\u0021 Хорошо, Р\u0430бота</string>
+    <string name="discount">20%discount on %s</string>
+    <string name="pattern" formatted="false">Use %s and %d</string>
+    <string name="styled">Tap <b>here</b> to <xliff:g id="action" example="start">%s</xliff:g></string>
+    <string-array name="planets">
+        <item>Mercury</item>
+        <item>Venus</item>
+    </string-array>
+    <!-- Number of songs -->
+    <plurals name="songs">
+        <item quantity="one">%d song found.</item>
+        <item quantity="other">%d songs found.</item>
+    </plurals>
+    <dimen name="margin">16dp</dimen>
+</resources>`
+
+func TestFromAndroidXML(t *testing.T) {
+	path := writeTempFile(t, "strings_*.xml", androidContent)
+
+	messages, err := FromAndroidXML(path)
+	assert.NoError(t, err)
+
+	expectedMessages := []message.Message{
+		{ID: "apostrophe", Other: "Don't panic\nKeep calm"},
+		{ID: "discount", Other: "20%discount on {{.Arg1}}"},
+		{ID: "escapes", Other: "@home ?mark 100%% é"},
+		{ID: "farewell", Other: "Voilà à bientôt! Хорошо, Работа"},
+		{ID: "pattern", Other: "Use %s and %d"},
+		{ID: "planets.0", Other: "Mercury"},
+		{ID: "planets.1", Other: "Venus"},
+		{ID: "quoted", Other: "  spaced   out  "},
+		{ID: "songs", Description: "Number of songs", One: `{{printf "%d" .Arg1}} song found.`, Other: `{{printf "%d" .Arg1}} songs found.`},
+		{ID: "styled", Other: "Tap here to {{.Arg1}}"},
+		{ID: "welcome", Description: "Shown on the start screen", Other: `Welcome, {{.Arg1}}! You have {{printf "%d" .Arg2}} new messages.`},
+	}
+	assert.Equal(t, expectedMessages, messages)
+}
+
+func TestFromAndroidXML_UnknownQuantity(t *testing.T) {
+	path := writeTempFile(t, "strings_*.xml", `<resources><plurals name="x"><item quantity="several">a</item></plurals></resources>`)
+
+	_, err := FromAndroidXML(path)
+	assert.ErrorContains(t, err, `unknown plural quantity "several"`)
+}
+
+func TestDetectXMLFormat(t *testing.T) {
+	format, err := DetectXMLFormat(writeTempFile(t, "strings_*.xml", androidContent))
+	assert.NoError(t, err)
+	assert.Equal(t, XMLFormatAndroid, format)
+
+	format, err = DetectXMLFormat(writeTempFile(t, "strings_*.xml", `<resources><greeting><string>Hello</string></greeting></resources>`))
+	assert.NoError(t, err)
+	assert.Equal(t, XMLFormatGeneric, format)
+
+	format, err = DetectXMLFormat(writeTempFile(t, "strings_*.xml", `<messages><string name="a">b</string></messages>`))
+	assert.NoError(t, err)
+	assert.Equal(t, XMLFormatGeneric, format)
+}
+
+func TestToAndroidXML(t *testing.T) {
+	messages := []message.Message{
+		{ID: "home.title", Description: "Title -- of the home screen", Other: "Don't forget @home & <b>"},
+		{ID: "planets.0", Other: "Mercury"},
+		{ID: "planets.1", Other: "Venus"},
+		{ID: "songs", One: "{{.Arg1}} song", Other: "{{.Arg1}} songs"},
+		{ID: "spaced", Other: "  keep  spaces\n"},
+		{ID: "welcome", Other: "?Welcome, {{.Arg1}}! 100% \"sure\""},
+		{ID: "sale", Other: "50% off"},
+		{ID: "pattern", Other: "Use %d for numbers"},
+	}
+
+	expected := `<?xml version="1.0" encoding="UTF-8"?>
+<resources>
+    <!-- Title - - of the home screen -->
+    <string name="home_title">Don\'t forget \@home &amp; &lt;b&gt;</string>
+    <string-array name="planets">
+        <item>Mercury</item>
+        <item>Venus</item>
+    </string-array>
+    <plurals name="songs">
+        <item quantity="one">%1$s song</item>
+        <item quantity="other">%1$s songs</item>
+    </plurals>
+    <string name="spaced">"  keep  spaces\n"</string>
+    <string name="greeting">\?Welcome, %1$s! 100%% \"sure\"</string>
+    <string name="sale">50% off</string>
+    <string name="pattern" formatted="false">Use %d for numbers</string>
+</resources>
+`
+	output, err := ToAndroidXMLWithOptions(messages, AndroidOptions{NameMapping: map[string]string{"welcome": "greeting"}})
+	assert.NoError(t, err)
+	assert.Equal(t, expected, output)
+}
+
+func TestToAndroidXML_NameCollision(t *testing.T) {
+	messages := []message.Message{
+		{ID: "home.title", Other: "a"},
+		{ID: "home_title", Other: "b"},
+	}
+	_, err := ToAndroidXML(messages)
+	assert.ErrorContains(t, err, `both map to Android resource name "home_title"`)
+}
+
+func TestToAndroidXML_RoundTrip(t *testing.T) {
+	messages := []message.Message{
+		{ID: "apostrophe", Description: "Careful", Other: "Don't \"quote\" me\non @this"},
+		{ID: "pattern", Other: "Use %d for numbers, 100%%"},
+		{ID: "planets.0", Other: "Mercury"},
+		{ID: "planets.1", Other: "Venus"},
+		{ID: "sale", Other: "50% off"},
+		{ID: "songs", One: "{{.Arg1}} song", Other: "{{.Arg1}} songs"},
+		{ID: "spaced", Other: " padded  text "},
+		{ID: "welcome", Other: "Welcome, {{.Arg1}}! 100% done"},
+	}
+	output, err := ToAndroidXML(messages)
+	assert.NoError(t, err)
+
+	parsed, err := FromAndroidXML(writeTempFile(t, "strings_*.xml", output))
+	assert.NoError(t, err)
+	assert.Equal(t, messages, parsed)
+}
+
+func TestAndroidResourcePath(t *testing.T) {
+	assert.Equal(t, "res/values/strings.xml", AndroidResourcePath("res", ""))
+	assert.Equal(t, "res/values-de/strings.xml", AndroidResourcePath("res", "de"))
+	assert.Equal(t, "res/values-pt-rBR/strings.xml", AndroidResourcePath("res", "pt_BR"))
+	assert.Equal(t, "res/values-en-rGB/strings.xml", AndroidResourcePath("res", "en-gb"))
+}
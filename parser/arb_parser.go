@@ -0,0 +1,333 @@
+package parser
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/s-nix/mk2i18n/message"
+)
+
+// ARBOptions configures how Flutter ARB files are written.
+type ARBOptions struct {
+	// Locale is written as @@locale. Omitted when empty.
+	Locale string
+}
+
+var (
+	// rICUArgument matches the start of an ICU plural, select or selectordinal argument, such as {count, plural,.
+	rICUArgument = regexp.MustCompile(`\{\s*(\w+)\s*,\s*(plural|select|selectordinal)\s*,`)
+
+	// rICUPlaceholder matches simple ICU placeholders, such as {name}.
+	rICUPlaceholder = regexp.MustCompile(`\{(\w+)\}`)
+
+	// rTemplateField matches simple template fields, such as {{.name}} or {{printf "%d" .count}}.
+	rTemplateField = regexp.MustCompile(`\{\{\s*(?:printf\s+"[^"]*"\s+)?\.(\w+)\s*\}\}`)
+)
+
+// icuExactPlurals maps the exact value selectors of ICU plurals to the plural category they stand in for.
+var icuExactPlurals = map[string]string{"=0": "zero", "=1": "one", "=2": "two"}
+
+// FromARB reads a Flutter Application Resource Bundle (.arb) file into messages.
+//
+// Every key that does not start with @ is a message ID, and the description of its @key metadata object
+// becomes the Description. Keys starting with @@, such as @@locale, are file attributes; see ARBLocale.
+// An ICU plural argument, such as {count, plural, =0{none} one{# item} other{# items}}, becomes the
+// plural forms, with the text around it added to every form, # replaced by the count and =0, =1 and =2 used as
+// zero, one and two when those categories are missing. As go-i18n selects forms by plural category only, an exact
+// selector whose category the @@locale does not use (English when there is none), such as =0 in English, is
+// reported as an error instead of being dropped. Placeholders such as {name} become template fields
+// such as {{.name}}. Select arguments and values with more than one plural argument are reported as errors.
+func FromARB(inputPath string) ([]message.Message, error) {
+	var data map[string]any
+	if err := DecodeJSONFile(inputPath, &data); err != nil {
+		return nil, err
+	}
+
+	locale, _ := data["@@locale"].(string)
+	var messages []message.Message
+	for id, value := range data {
+		if strings.HasPrefix(id, "@") {
+			continue
+		}
+		text, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("%s: value of %q is not a string", inputPath, id)
+		}
+		msg, err := arbMessage(id, text, locale)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", inputPath, err)
+		}
+		if description, ok := jsonObject(data["@"+id])["description"].(string); ok {
+			msg.Description = description
+		}
+		messages = append(messages, msg)
+	}
+
+	if len(messages) == 0 {
+		return nil, nil
+	}
+	sort.Slice(messages, func(i, j int) bool {
+		return messages[i].ID < messages[j].ID
+	})
+	return messages, nil
+}
+
+// ARBLocale returns the @@locale attribute of an ARB file, or an empty string when it has none.
+func ARBLocale(inputPath string) (string, error) {
+	var data map[string]any
+	if err := DecodeJSONFile(inputPath, &data); err != nil {
+		return "", err
+	}
+	locale, _ := data["@@locale"].(string)
+	return locale, nil
+}
+
+// ToARB converts a slice of message.Message objects into a Flutter ARB file without a locale.
+func ToARB(messages []message.Message) (string, error) {
+	return ToARBWithOptions(messages, ARBOptions{})
+}
+
+// ToARBWithOptions converts a slice of message.Message objects into a Flutter ARB file.
+//
+// Each message is written as its value followed by an @key metadata object with its Description and the
+// placeholders it uses. Template fields such as {{.name}} become ICU placeholders such as {name}, and plural
+// messages become an ICU plural argument on their count placeholder, such as count or PluralCount, otherwise on the
+// first placeholder that every plural form uses, or on count when there is none.
+// Plural placeholders are typed int, the other placeholders String.
+func ToARBWithOptions(messages []message.Message, opts ARBOptions) (string, error) {
+	type arbEntry struct {
+		key   string
+		value any
+	}
+	var entries []arbEntry
+	if opts.Locale != "" {
+		entries = append(entries, arbEntry{"@@locale", opts.Locale})
+	}
+
+	for _, msg := range messages {
+		var placeholders []string
+		seen := map[string]bool{}
+		collect := func(text string) {
+			for _, match := range rTemplateField.FindAllStringSubmatch(text, -1) {
+				if !seen[match[1]] {
+					seen[match[1]] = true
+					placeholders = append(placeholders, match[1])
+				}
+			}
+		}
+
+		value := rTemplateField.ReplaceAllString(msg.Other, "{$1}")
+		selector := ""
+		if msg.IsPlural() {
+			selector = arbPluralSelector(msg)
+			seen[selector] = true
+			placeholders = append(placeholders, selector)
+			var sb strings.Builder
+			sb.WriteString("{" + selector + ", plural,")
+			for _, category := range message.PluralCategories {
+				if form := msg.PluralForm(category); form != "" {
+					collect(form)
+					sb.WriteString(" " + category + "{" + rTemplateField.ReplaceAllString(form, "{$1}") + "}")
+				}
+			}
+			sb.WriteString("}")
+			value = sb.String()
+		} else {
+			collect(msg.Other)
+		}
+		entries = append(entries, arbEntry{msg.ID, value})
+
+		metadata := map[string]any{}
+		if msg.Description != "" {
+			metadata["description"] = msg.Description
+		}
+		if len(placeholders) > 0 {
+			types := map[string]any{}
+			for _, name := range placeholders {
+				placeholderType := "String"
+				if name == selector {
+					placeholderType = "int"
+				}
+				types[name] = map[string]any{"type": placeholderType}
+			}
+			metadata["placeholders"] = types
+		}
+		if len(metadata) > 0 {
+			entries = append(entries, arbEntry{"@" + msg.ID, metadata})
+		}
+	}
+
+	var sb strings.Builder
+	sb.WriteString("{")
+	for i, entry := range entries {
+		if i > 0 {
+			sb.WriteString(",")
+		}
+		var buf bytes.Buffer
+		encoder := json.NewEncoder(&buf)
+		encoder.SetEscapeHTML(false)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(map[string]any{entry.key: entry.value}); err != nil {
+			return "", err
+		}
+		// Strip the braces of the single entry object so the entries share one object in the given order.
+		inner := strings.TrimSpace(buf.String())
+		inner = strings.TrimSpace(inner[1 : len(inner)-1])
+		sb.WriteString("\n  " + inner)
+	}
+	sb.WriteString("\n}\n")
+	return sb.String(), nil
+}
+
+// arbPluralSelector returns the placeholder the ICU plural argument of a plural message selects on.
+// A placeholder named count or PluralCount, in any case, is preferred over the first placeholder
+// of the other form that every plural form uses, and count is used when neither exists.
+func arbPluralSelector(msg message.Message) string {
+	var forms []string
+	for _, category := range message.PluralCategories {
+		if form := msg.PluralForm(category); form != "" {
+			forms = append(forms, form)
+		}
+	}
+	for _, form := range forms {
+		for _, match := range rTemplateField.FindAllStringSubmatch(form, -1) {
+			if strings.EqualFold(match[1], "count") || strings.EqualFold(match[1], "pluralcount") {
+				return match[1]
+			}
+		}
+	}
+	for _, match := range rTemplateField.FindAllStringSubmatch(msg.Other, -1) {
+		common := true
+		for _, form := range forms {
+			if !slices.ContainsFunc(rTemplateField.FindAllStringSubmatch(form, -1), func(field []string) bool {
+				return field[1] == match[1]
+			}) {
+				common = false
+				break
+			}
+		}
+		if common {
+			return match[1]
+		}
+	}
+	return "count"
+}
+
+// arbMessage builds a message from an ARB value of the given locale, resolving an ICU plural argument into plural forms.
+func arbMessage(id string, value string, locale string) (message.Message, error) {
+	msg := message.Message{ID: id}
+	locations := rICUArgument.FindAllStringSubmatchIndex(value, -1)
+	if len(locations) == 0 {
+		msg.Other = rICUPlaceholder.ReplaceAllString(value, "{{.$1}}")
+		return msg, nil
+	}
+	location := locations[0]
+	variable := value[location[2]:location[3]]
+	if kind := value[location[4]:location[5]]; kind != "plural" {
+		return msg, fmt.Errorf("%q uses an ICU %s argument, which is not supported", id, kind)
+	}
+
+	options, end, err := parseICUOptions(value, location[1])
+	if err != nil {
+		return msg, fmt.Errorf("%q: %w", id, err)
+	}
+	if rICUArgument.MatchString(value[end:]) {
+		return msg, fmt.Errorf("%q uses more than one ICU argument, which is not supported", id)
+	}
+
+	prefix, suffix := value[:location[0]], value[end:]
+	exact := map[string]string{}
+	for _, option := range options {
+		if !strings.HasPrefix(option[0], "=") {
+			continue
+		}
+		category, ok := icuExactPlurals[option[0]]
+		if !ok {
+			return msg, fmt.Errorf("%q uses the plural selector %s, which is not supported", id, option[0])
+		}
+		exact[category] = option[1]
+	}
+	for _, option := range options {
+		if strings.HasPrefix(option[0], "=") {
+			continue
+		}
+		form := prefix + strings.ReplaceAll(option[1], "#", "{"+variable+"}") + suffix
+		if !msg.SetPluralForm(option[0], rICUPlaceholder.ReplaceAllString(form, "{{.$1}}")) {
+			return msg, fmt.Errorf("%q uses unknown plural category %q", id, option[0])
+		}
+	}
+	categories := pluralRuleForLocale(locale).categories
+	for _, option := range options {
+		category, ok := icuExactPlurals[option[0]]
+		if !ok || msg.PluralForm(category) != "" || slices.Contains(categories, category) {
+			continue
+		}
+		if locale == "" {
+			locale = "en"
+		}
+		return msg, fmt.Errorf("%q uses the plural selector %s, but %s has no %s plural form, so its text would never be used", id, option[0], locale, category)
+	}
+	for category, text := range exact {
+		if msg.PluralForm(category) == "" {
+			form := prefix + strings.ReplaceAll(text, "#", "{"+variable+"}") + suffix
+			msg.SetPluralForm(category, rICUPlaceholder.ReplaceAllString(form, "{{.$1}}"))
+		}
+	}
+	if msg.Other == "" {
+		return msg, fmt.Errorf("%q has no other form in its plural argument", id)
+	}
+	return msg, nil
+}
+
+// parseICUOptions parses the selector{message} pairs of an ICU argument, starting after its keyword.
+// It returns the pairs and the position after the closing brace of the argument.
+func parseICUOptions(value string, position int) ([][2]string, int, error) {
+	var options [][2]string
+	for {
+		for position < len(value) && unicode.IsSpace(rune(value[position])) {
+			position++
+		}
+		if position >= len(value) {
+			return nil, 0, fmt.Errorf("unterminated ICU argument")
+		}
+		if value[position] == '}' {
+			return options, position + 1, nil
+		}
+
+		start := position
+		for position < len(value) && value[position] != '{' && !unicode.IsSpace(rune(value[position])) {
+			position++
+		}
+		selector := value[start:position]
+		for position < len(value) && unicode.IsSpace(rune(value[position])) {
+			position++
+		}
+		if position >= len(value) || value[position] != '{' {
+			return nil, 0, fmt.Errorf("expected '{' after ICU selector %q", selector)
+		}
+
+		depth := 0
+		start = position + 1
+		for ; position < len(value); position++ {
+			if value[position] == '{' {
+				depth++
+			} else if value[position] == '}' {
+				depth--
+				if depth == 0 {
+					break
+				}
+			}
+		}
+		if position >= len(value) {
+			return nil, 0, fmt.Errorf("unterminated ICU message for selector %q", selector)
+		}
+		options = append(options, [2]string{selector, value[start:position]})
+		position++
+	}
+}
@@ -0,0 +1,140 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/s-nix/mk2i18n/message"
+	"github.com/stretchr/testify/assert"
+)
+
+const arbContent = `{
+  "@@locale": "de",
+  "@@last_modified": "2024-01-01",
+  "greeting": "Hallo {name}",
+  "@greeting": {
+    "description": "Greeting on the start screen",
+    "placeholders": {
+      "name": {"type": "String", "example": "Bob"}
+    }
+  },
+  "items": "Du hast {count, plural, =1{einen Eintrag} other{# Einträge}}.",
+  "@items": {
+    "placeholders": {
+      "count": {"type": "int"}
+    }
+  },
+  "title": "Start"
+}`
+
+func TestFromARB(t *testing.T) {
+	path := writeTempFile(t, "app_*.arb", arbContent)
+
+	messages, err := FromARB(path)
+	assert.NoError(t, err)
+
+	expectedMessages := []message.Message{
+		{ID: "greeting", Description: "Greeting on the start screen", Other: "Hallo {{.name}}"},
+		{ID: "items", One: "Du hast einen Eintrag.", Other: "Du hast {{.count}} Einträge."},
+		{ID: "title", Other: "Start"},
+	}
+	assert.Equal(t, expectedMessages, messages)
+
+	locale, err := ARBLocale(path)
+	assert.NoError(t, err)
+	assert.Equal(t, "de", locale)
+
+	messages, err = FromARB(writeTempFile(t, "app_*.arb", `{"@@locale": "lv", "files": "{count, plural, =0{nav failu} one{# fails} other{# faili}}"}`))
+	assert.NoError(t, err)
+	assert.Equal(t, []message.Message{{ID: "files", Zero: "nav failu", One: "{{.count}} fails", Other: "{{.count}} faili"}}, messages)
+}
+
+func TestFromARB_Unsupported(t *testing.T) {
+	_, err := FromARB(writeTempFile(t, "app_*.arb", `{"pronoun": "{gender, select, male{he} other{they}}"}`))
+	assert.ErrorContains(t, err, `"pronoun" uses an ICU select argument, which is not supported`)
+
+	_, err = FromARB(writeTempFile(t, "app_*.arb", `{"items": "{count, plural, =5{five} other{many}}"}`))
+	assert.ErrorContains(t, err, `"items" uses the plural selector =5, which is not supported`)
+
+	_, err = FromARB(writeTempFile(t, "app_*.arb", `{"@@locale": "de", "items": "{count, plural, =0{keine} other{viele}}"}`))
+	assert.ErrorContains(t, err, `"items" uses the plural selector =0, but de has no zero plural form`)
+
+	_, err = FromARB(writeTempFile(t, "app_*.arb", `{"items": "{count, plural, =2{both} other{many}}"}`))
+	assert.ErrorContains(t, err, `"items" uses the plural selector =2, but en has no two plural form`)
+
+	_, err = FromARB(writeTempFile(t, "app_*.arb", `{"items": "{count, plural, one{one}"}`))
+	assert.ErrorContains(t, err, "unterminated ICU argument")
+}
+
+func TestToARB(t *testing.T) {
+	messages := []message.Message{
+		{ID: "greeting", Description: "Greeting on the start screen", Other: "Hallo {{.name}}"},
+		{ID: "items", One: "{{.count}} Eintrag von {{.user}}", Other: "{{.count}} Einträge von {{.user}}"},
+		{ID: "title", Other: "Start & Ziel"},
+	}
+	output, err := ToARBWithOptions(messages, ARBOptions{Locale: "de"})
+	assert.NoError(t, err)
+
+	expected := `{
+  "@@locale": "de",
+  "greeting": "Hallo {name}",
+  "@greeting": {
+    "description": "Greeting on the start screen",
+    "placeholders": {
+      "name": {
+        "type": "String"
+      }
+    }
+  },
+  "items": "{count, plural, one{{count} Eintrag von {user}} other{{count} Einträge von {user}}}",
+  "@items": {
+    "placeholders": {
+      "count": {
+        "type": "int"
+      },
+      "user": {
+        "type": "String"
+      }
+    }
+  },
+  "title": "Start & Ziel"
+}
+`
+	assert.Equal(t, expected, output)
+
+	roundTrip, err := FromARB(writeTempFile(t, "app_*.arb", output))
+	assert.NoError(t, err)
+	assert.Equal(t, messages, roundTrip)
+}
+
+func TestToARB_PluralSelector(t *testing.T) {
+	messages := []message.Message{
+		{ID: "inbox", One: "{{.Name}} has {{.Count}} item", Other: "{{.Name}} has {{.Count}} items"},
+	}
+	output, err := ToARB(messages)
+	assert.NoError(t, err)
+
+	expected := `{
+  "inbox": "{Count, plural, one{{Name} has {Count} item} other{{Name} has {Count} items}}",
+  "@inbox": {
+    "placeholders": {
+      "Count": {
+        "type": "int"
+      },
+      "Name": {
+        "type": "String"
+      }
+    }
+  }
+}
+`
+	assert.Equal(t, expected, output)
+
+	tests := map[string]message.Message{
+		"PluralCount": {One: "{{.Name}} has one {{.Kind}}", Other: "{{.Name}} has {{.PluralCount}} {{.Kind}}s"},
+		"n":           {Zero: "no files ({{.n}})", One: "{{.n}} file", Other: "{{.dir}} has {{.n}} files"},
+		"count":       {One: "one file", Other: "{{.total}} files"},
+	}
+	for expected, msg := range tests {
+		assert.Equal(t, expected, arbPluralSelector(msg), msg.Other)
+	}
+}
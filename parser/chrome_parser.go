@@ -0,0 +1,174 @@
+package parser
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/s-nix/mk2i18n/message"
+)
+
+// chromeEntry is an entry of a Chrome extension messages.json file.
+type chromeEntry struct {
+	Message      string                       `json:"message"`
+	Description  string                       `json:"description,omitempty"`
+	Placeholders map[string]chromePlaceholder `json:"placeholders,omitempty"`
+}
+
+// chromePlaceholder is a named placeholder of a Chrome extension message.
+type chromePlaceholder struct {
+	Content string `json:"content"`
+	Example string `json:"example,omitempty"`
+}
+
+var (
+	// rChromeReference matches named placeholders ($USER$), positional substitutions ($1) and escaped dollar signs ($$).
+	rChromeReference = regexp.MustCompile(`\$(?:([a-zA-Z0-9_@]+)\$|([1-9])|\$)`)
+
+	// rChromeContent matches the substitutions ($1) and escaped dollar signs ($$) in placeholder content.
+	rChromeContent = regexp.MustCompile(`\$(?:([1-9])|\$)`)
+
+	// rChromeInvalidName matches the characters that are not allowed in Chrome message names.
+	rChromeInvalidName = regexp.MustCompile(`[^A-Za-z0-9_@]`)
+)
+
+// FromChromeMessages reads a Chrome or WebExtension _locales/<lang>/messages.json file into messages.
+//
+// The name of each entry is the message ID, its message is Other and its description is the Description.
+// Named placeholders such as $USER$ are expanded: a placeholder whose content is a substitution such as $1
+// becomes a template field with its name, such as {{.user}}, and other content is inserted as it is.
+// Substitutions used directly in the message, such as $1, become {{.Arg1}}, and $$ becomes $.
+func FromChromeMessages(inputPath string) ([]message.Message, error) {
+	var entries map[string]chromeEntry
+	if err := DecodeJSONFile(inputPath, &entries); err != nil {
+		return nil, err
+	}
+
+	var messages []message.Message
+	for id, entry := range entries {
+		placeholders := map[string]string{}
+		for name := range entry.Placeholders {
+			placeholders[strings.ToLower(name)] = name
+		}
+
+		var expandErr error
+		other := rChromeReference.ReplaceAllStringFunc(entry.Message, func(reference string) string {
+			match := rChromeReference.FindStringSubmatch(reference)
+			switch {
+			case match[2] != "":
+				return "{{.Arg" + match[2] + "}}"
+			case match[1] == "":
+				return "$"
+			}
+			name, ok := placeholders[strings.ToLower(match[1])]
+			if !ok {
+				expandErr = fmt.Errorf("%s: message %q uses undefined placeholder %s", inputPath, id, reference)
+				return reference
+			}
+			content := entry.Placeholders[name].Content
+			if match := rChromeContent.FindStringSubmatch(content); match != nil && match[0] == content && match[1] != "" {
+				return "{{." + name + "}}"
+			}
+			return rChromeContent.ReplaceAllStringFunc(content, func(substitution string) string {
+				if substitution == "$$" {
+					return "$"
+				}
+				return "{{.Arg" + substitution[1:] + "}}"
+			})
+		})
+		if expandErr != nil {
+			return nil, expandErr
+		}
+		messages = append(messages, message.Message{ID: id, Description: entry.Description, Other: other})
+	}
+
+	if len(messages) == 0 {
+		return nil, nil
+	}
+	sort.Slice(messages, func(i, j int) bool {
+		return messages[i].ID < messages[j].ID
+	})
+	return messages, nil
+}
+
+// ToChromeMessages converts a slice of message.Message objects into a Chrome extension messages.json file.
+//
+// Characters that are not allowed in message names are replaced with underscores, so home.title becomes
+// home_title; two IDs giving the same name are an error. Template fields such as {{.user}} become named
+// placeholders such as $USER$. Positional fields keep their index, so {{.Arg2}} gets the substitution $2, and the
+// other fields are numbered in order of appearance after the highest index. $ is escaped as $$.
+// Chrome messages have no plural forms, so plural messages are written with their Other form.
+func ToChromeMessages(messages []message.Message) (string, error) {
+	entries := map[string]chromeEntry{}
+	ids := map[string]string{}
+	for _, msg := range messages {
+		name := rChromeInvalidName.ReplaceAllString(msg.ID, "_")
+		if other, exists := ids[name]; exists {
+			return "", fmt.Errorf("message IDs %q and %q both map to Chrome message name %q", other, msg.ID, name)
+		}
+		ids[name] = msg.ID
+
+		entry := chromeEntry{Description: msg.Description}
+		next := 0
+		for _, match := range rTemplateArg.FindAllStringSubmatch(msg.Other, -1) {
+			if arg, _ := strconv.Atoi(match[1]); arg > next {
+				next = arg
+			}
+		}
+		text := strings.ReplaceAll(msg.Other, "$", "$$")
+		entry.Message = rTemplateField.ReplaceAllStringFunc(text, func(field string) string {
+			placeholder := rTemplateField.FindStringSubmatch(field)[1]
+			if entry.Placeholders == nil {
+				entry.Placeholders = map[string]chromePlaceholder{}
+			}
+			if _, exists := entry.Placeholders[placeholder]; !exists {
+				arg, isArg := strings.CutPrefix(placeholder, "Arg")
+				if _, err := strconv.Atoi(arg); !isArg || err != nil {
+					next++
+					arg = strconv.Itoa(next)
+				}
+				entry.Placeholders[placeholder] = chromePlaceholder{Content: "$" + arg}
+			}
+			return "$" + strings.ToUpper(placeholder) + "$"
+		})
+		entries[name] = entry
+	}
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(entries); err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(buf.String(), "\n"), nil
+}
+
+// DetectJSONFormat reports the flavor of a JSON file.
+// A Chrome extension message file is detected when every top-level value is an object with a message string
+// and no keys other than message, description and placeholders. Everything else is generic JSON.
+func DetectJSONFormat(inputPath string) (JSONFormat, error) {
+	var data map[string]any
+	if err := DecodeJSONFile(inputPath, &data); err != nil {
+		return JSONFormatAuto, err
+	}
+	if len(data) == 0 {
+		return JSONFormatGeneric, nil
+	}
+	for _, value := range data {
+		entry := jsonObject(value)
+		if _, ok := entry["message"].(string); !ok {
+			return JSONFormatGeneric, nil
+		}
+		for key := range entry {
+			if key != "message" && key != "description" && key != "placeholders" {
+				return JSONFormatGeneric, nil
+			}
+		}
+	}
+	return JSONFormatChrome, nil
+}
@@ -0,0 +1,108 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/s-nix/mk2i18n/message"
+	"github.com/stretchr/testify/assert"
+)
+
+const chromeContent = `{
+  "extName": {
+    "message": "My Extension",
+    "description": "Name of the extension"
+  },
+  "greeting": {
+    "message": "Hello $User$, you owe $AMOUNT$ ($$) for $1 from $Site$",
+    "placeholders": {
+      "user": {"content": "$1", "example": "Bob"},
+      "amount": {"content": "$2 USD"},
+      "site": {"content": "example.com"}
+    }
+  }
+}`
+
+func TestFromChromeMessages(t *testing.T) {
+	messages, err := FromChromeMessages(writeTempFile(t, "messages_*.json", chromeContent))
+	assert.NoError(t, err)
+
+	expectedMessages := []message.Message{
+		{ID: "extName", Description: "Name of the extension", Other: "My Extension"},
+		{ID: "greeting", Other: "Hello {{.user}}, you owe {{.Arg2}} USD ($) for {{.Arg1}} from example.com"},
+	}
+	assert.Equal(t, expectedMessages, messages)
+
+	_, err = FromChromeMessages(writeTempFile(t, "messages_*.json", `{"a": {"message": "Hi $NAME$"}}`))
+	assert.ErrorContains(t, err, `message "a" uses undefined placeholder $NAME$`)
+}
+
+func TestToChromeMessages(t *testing.T) {
+	messages := []message.Message{
+		{ID: "greeting", Description: "Greeting", Other: "Hello {{.user}}, that is $5 for {{.count}} & {{.user}}"},
+		{ID: "home.title", Other: "Home"},
+	}
+	output, err := ToChromeMessages(messages)
+	assert.NoError(t, err)
+
+	expected := `{
+  "greeting": {
+    "message": "Hello $USER$, that is $$5 for $COUNT$ & $USER$",
+    "description": "Greeting",
+    "placeholders": {
+      "count": {
+        "content": "$2"
+      },
+      "user": {
+        "content": "$1"
+      }
+    }
+  },
+  "home_title": {
+    "message": "Home"
+  }
+}`
+	assert.Equal(t, expected, output)
+
+	roundTrip, err := FromChromeMessages(writeTempFile(t, "messages_*.json", output))
+	assert.NoError(t, err)
+	assert.Equal(t, []message.Message{
+		messages[0],
+		{ID: "home_title", Other: "Home"},
+	}, roundTrip)
+
+	output, err = ToChromeMessages([]message.Message{{ID: "owe", Other: "{{.user}} owes {{.Arg2}} for {{.Arg1}}"}})
+	assert.NoError(t, err)
+	assert.Equal(t, `{
+  "owe": {
+    "message": "$USER$ owes $ARG2$ for $ARG1$",
+    "placeholders": {
+      "Arg1": {
+        "content": "$1"
+      },
+      "Arg2": {
+        "content": "$2"
+      },
+      "user": {
+        "content": "$3"
+      }
+    }
+  }
+}`, output, "positional fields keep their substitution index")
+
+	_, err = ToChromeMessages([]message.Message{{ID: "a.b", Other: "1"}, {ID: "a_b", Other: "2"}})
+	assert.ErrorContains(t, err, `message IDs "a.b" and "a_b" both map to Chrome message name "a_b"`)
+}
+
+func TestDetectJSONFormat(t *testing.T) {
+	format, err := DetectJSONFormat(writeTempFile(t, "messages_*.json", chromeContent))
+	assert.NoError(t, err)
+	assert.Equal(t, JSONFormatChrome, format)
+
+	format, err = DetectJSONFormat(writeTempFile(t, "messages_*.json", `{"greeting": {"description": "Hi", "other": "Hello"}}`))
+	assert.NoError(t, err)
+	assert.Equal(t, JSONFormatGeneric, format)
+
+	format, err = DetectJSONFormat(writeTempFile(t, "messages_*.json", `{"a": {"message": "x", "other": "y"}}`))
+	assert.NoError(t, err)
+	assert.Equal(t, JSONFormatGeneric, format)
+}
@@ -0,0 +1,347 @@
+package parser
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/s-nix/mk2i18n/message"
+)
+
+// CSVOptions configures how CSV and TSV spreadsheets are read and written, and how XLSX workbooks are read.
+type CSVOptions struct {
+	// Columns is the column layout, such as id, description, one, other. The names id and description and
+	// the plural categories are message fields; any other name is a locale column, such as de, holding the other
+	// form in that locale, and a locale with a plural category suffix, such as de.one, holds that form.
+	// When empty, the layout is taken from the header row, or is id, description, other for files without one.
+	Columns []string
+
+	// Locale selects the locale columns to read and write. When the file has no columns for it, or it is empty,
+	// the columns without a locale are read, or the first locale column when there are none.
+	Locale string
+
+	// Comma is the field separator. Defaults to ',' and is '\t' for TSV files.
+	Comma rune
+
+	// Sheet is the name of the worksheet read from XLSX workbooks. Defaults to the first sheet.
+	Sheet string
+}
+
+// csvColumn is the message field and locale of a spreadsheet column.
+type csvColumn struct {
+	field  string
+	locale string
+}
+
+// defaultCSVColumns is the layout of files without a header row when no columns are given.
+var defaultCSVColumns = []string{"id", "description", "other"}
+
+// FromCSV reads a comma separated spreadsheet into messages, with the column layout of its header row.
+func FromCSV(inputPath string) ([]message.Message, error) {
+	return FromCSVWithOptions(inputPath, CSVOptions{})
+}
+
+// FromCSVWithOptions reads a CSV or TSV spreadsheet into messages, with one message per row.
+//
+// The first row is a header when one of its cells is id, in any case, and then gives the column layout unless
+// Columns is set. Quoted fields follow RFC 4180, so values may contain separators, quotes and newlines.
+// Rows without any value, and rows without a value in the locale columns read, are skipped; a row without
+// an ID and a repeated ID are reported as errors.
+func FromCSVWithOptions(inputPath string, opts CSVOptions) ([]message.Message, error) {
+	file, err := os.Open(inputPath)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+	return messagesFromRecords(inputPath, csvRecords(file, opts), opts)
+}
+
+// CSVLocales returns the locales of the locale columns of a CSV or TSV spreadsheet, in column order.
+// The columns are Columns, or those of the header row.
+func CSVLocales(inputPath string, opts CSVOptions) ([]string, error) {
+	file, err := os.Open(inputPath)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+	return localesFromRecords(inputPath, csvRecords(file, opts), opts)
+}
+
+// recordFunc returns the next row of a spreadsheet and its line or row number, or io.EOF after the last row.
+type recordFunc func() (record []string, line int, err error)
+
+// csvRecords returns a recordFunc reading the rows of a CSV or TSV file.
+func csvRecords(file io.Reader, opts CSVOptions) recordFunc {
+	reader := csv.NewReader(file)
+	reader.Comma = csvComma(opts)
+	reader.FieldsPerRecord = -1
+	first := true
+	return func() ([]string, int, error) {
+		record, err := reader.Read()
+		if err != nil {
+			return nil, 0, err
+		}
+		if first {
+			first = false
+			record[0] = strings.TrimPrefix(record[0], "\ufeff")
+		}
+		line, _ := reader.FieldPos(0)
+		return record, line, nil
+	}
+}
+
+// messagesFromRecords maps the rows of a spreadsheet to messages using the column layout of opts
+// or of the header row.
+func messagesFromRecords(inputPath string, next recordFunc, opts CSVOptions) ([]message.Message, error) {
+	header, headerLine, err := next()
+	if err == io.EOF {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", inputPath, err)
+	}
+
+	names := opts.Columns
+	record, line := header, headerLine
+	if isCSVHeader(header) {
+		record = nil
+		if len(names) == 0 {
+			names = header
+		}
+	}
+	if len(names) == 0 {
+		names = defaultCSVColumns
+	}
+	columns, err := parseCSVColumns(names)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", inputPath, err)
+	}
+	locale, err := csvReadLocale(columns, opts.Locale)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", inputPath, err)
+	}
+
+	var messages []message.Message
+	seen := map[string]int{}
+	for {
+		if record == nil {
+			record, line, err = next()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", inputPath, err)
+			}
+		}
+		cells := record
+		record = nil
+		if strings.Join(cells, "") == "" {
+			continue
+		}
+
+		var msg message.Message
+		for i, cell := range cells {
+			if i >= len(columns) {
+				break
+			}
+			switch column := columns[i]; {
+			case column.field == "id":
+				msg.ID = cell
+			case column.field == "description":
+				msg.Description = cell
+			case column.field != "" && strings.EqualFold(column.locale, locale):
+				msg.SetPluralForm(column.field, cell)
+			}
+		}
+		if msg.ID == "" {
+			return nil, fmt.Errorf("%s:%d: row has no message ID", inputPath, line)
+		}
+		if locale != "" && !msg.IsPlural() && msg.Other == "" {
+			// The message is not translated into this locale.
+			continue
+		}
+		if previous, ok := seen[msg.ID]; ok {
+			return nil, fmt.Errorf("%s:%d: duplicate message ID %q, first defined on line %d", inputPath, line, msg.ID, previous)
+		}
+		seen[msg.ID] = line
+		messages = append(messages, msg)
+	}
+
+	if len(messages) == 0 {
+		return nil, nil
+	}
+	sort.Slice(messages, func(i, j int) bool {
+		return messages[i].ID < messages[j].ID
+	})
+	return messages, nil
+}
+
+// localesFromRecords returns the locales of the locale columns of Columns, or of the header row.
+func localesFromRecords(inputPath string, next recordFunc, opts CSVOptions) ([]string, error) {
+	names := opts.Columns
+	if len(names) == 0 {
+		header, _, err := next()
+		if err != nil && err != io.EOF {
+			return nil, fmt.Errorf("%s: %w", inputPath, err)
+		}
+		if !isCSVHeader(header) {
+			return nil, fmt.Errorf("%s: no header row naming the locale columns", inputPath)
+		}
+		names = header
+	}
+	columns, err := parseCSVColumns(names)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", inputPath, err)
+	}
+
+	var locales []string
+	for _, column := range columns {
+		if column.locale != "" && !slices.ContainsFunc(locales, func(locale string) bool { return strings.EqualFold(locale, column.locale) }) {
+			locales = append(locales, column.locale)
+		}
+	}
+	if len(locales) == 0 {
+		return nil, fmt.Errorf("%s: no locale columns in %s", inputPath, strings.Join(names, ", "))
+	}
+	return locales, nil
+}
+
+// ToCSV converts a slice of message.Message objects into a comma separated spreadsheet with a header row.
+func ToCSV(messages []message.Message) (string, error) {
+	return ToCSVWithOptions(messages, CSVOptions{})
+}
+
+// ToCSVWithOptions converts a slice of message.Message objects into a CSV or TSV spreadsheet with a header row.
+//
+// The columns are Columns, or id, description, the plural categories used by the messages and other.
+// Locale columns are filled for the Locale only, and the other locale columns are left empty.
+// Fields are quoted following RFC 4180, lines end with CRLF and the file starts with a UTF-8 byte order mark,
+// so that Excel and Google Sheets read it back unchanged.
+func ToCSVWithOptions(messages []message.Message, opts CSVOptions) (string, error) {
+	names := opts.Columns
+	if len(names) == 0 {
+		names = []string{"id", "description"}
+		for _, category := range message.PluralCategories[:len(message.PluralCategories)-1] {
+			for _, msg := range messages {
+				if msg.PluralForm(category) != "" {
+					names = append(names, category)
+					break
+				}
+			}
+		}
+		names = append(names, "other")
+	}
+	columns, err := parseCSVColumns(names)
+	if err != nil {
+		return "", err
+	}
+
+	var sb strings.Builder
+	sb.WriteString("\ufeff")
+	writer := csv.NewWriter(&sb)
+	writer.Comma = csvComma(opts)
+	writer.UseCRLF = true
+	if err := writer.Write(names); err != nil {
+		return "", err
+	}
+	for _, msg := range messages {
+		record := make([]string, len(columns))
+		for i, column := range columns {
+			switch {
+			case column.field == "id":
+				record[i] = msg.ID
+			case column.field == "description":
+				record[i] = msg.Description
+			case column.field != "" && (column.locale == "" || strings.EqualFold(column.locale, opts.Locale)):
+				record[i] = msg.PluralForm(column.field)
+			}
+		}
+		if err := writer.Write(record); err != nil {
+			return "", err
+		}
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return "", err
+	}
+	return sb.String(), nil
+}
+
+// parseCSVColumns resolves column names into message fields and locales.
+func parseCSVColumns(names []string) ([]csvColumn, error) {
+	columns := make([]csvColumn, len(names))
+	hasID := false
+	seen := map[string]bool{}
+	for i, name := range names {
+		name = strings.TrimSpace(name)
+		lower := strings.ToLower(name)
+		if seen[lower] && lower != "" {
+			return nil, fmt.Errorf("column %q is given more than once", name)
+		}
+		seen[lower] = true
+
+		switch index := strings.LastIndex(lower, "."); {
+		case lower == "id" || lower == "description" || slices.Contains(message.PluralCategories, lower):
+			columns[i] = csvColumn{field: lower}
+		case index > 0 && slices.Contains(message.PluralCategories, lower[index+1:]):
+			columns[i] = csvColumn{field: lower[index+1:], locale: name[:index]}
+		case name == "":
+			columns[i] = csvColumn{}
+		default:
+			columns[i] = csvColumn{field: "other", locale: name}
+		}
+		hasID = hasID || lower == "id"
+	}
+	if !hasID {
+		return nil, fmt.Errorf("no id column in %s", strings.Join(names, ", "))
+	}
+	return columns, nil
+}
+
+// csvReadLocale returns the locale whose columns are read: the given locale when it has columns, otherwise
+// the empty locale of the columns without one, or the first locale column when no locale is given.
+func csvReadLocale(columns []csvColumn, locale string) (string, error) {
+	first, unlocalized := "", false
+	for _, column := range columns {
+		if column.field == "id" || column.field == "description" || column.field == "" {
+			continue
+		}
+		if locale != "" && strings.EqualFold(column.locale, locale) {
+			return locale, nil
+		}
+		unlocalized = unlocalized || column.locale == ""
+		if first == "" {
+			first = column.locale
+		}
+	}
+	switch {
+	case unlocalized:
+		return "", nil
+	case locale != "":
+		return "", fmt.Errorf("no column for locale %q", locale)
+	}
+	return first, nil
+}
+
+// isCSVHeader reports whether a record is a header row, that is, whether one of its cells is id in any case.
+func isCSVHeader(record []string) bool {
+	return slices.ContainsFunc(record, func(cell string) bool {
+		return strings.EqualFold(strings.TrimSpace(cell), "id")
+	})
+}
+
+// csvComma returns the field separator of opts.
+func csvComma(opts CSVOptions) rune {
+	if opts.Comma == 0 {
+		return ','
+	}
+	return opts.Comma
+}
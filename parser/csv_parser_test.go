@@ -0,0 +1,98 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/s-nix/mk2i18n/message"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFromCSV(t *testing.T) {
+	content := "\ufeffID,Description,one,other\r\n" +
+		"items,Item count,\"{{.Count}} item\",\"{{.Count}} items\"\r\n" +
+		"\r\n" +
+		"quote,\"Says \"\"hi\"\", twice\",,\"line one\nline two\"\r\n"
+	messages, err := FromCSV(writeTempFile(t, "messages_*.csv", content))
+	assert.NoError(t, err)
+
+	expectedMessages := []message.Message{
+		{ID: "items", Description: "Item count", One: "{{.Count}} item", Other: "{{.Count}} items"},
+		{ID: "quote", Description: "Says \"hi\", twice", Other: "line one\nline two"},
+	}
+	assert.Equal(t, expectedMessages, messages)
+
+	_, err = FromCSV(writeTempFile(t, "messages_*.csv", "id,other\na,1\nb,2\na,3\n"))
+	assert.ErrorContains(t, err, `:4: duplicate message ID "a", first defined on line 2`)
+
+	_, err = FromCSVWithOptions(writeTempFile(t, "messages_*.csv", "key,other\na,1\n"), CSVOptions{Columns: []string{"key", "other"}})
+	assert.ErrorContains(t, err, "no id column in key, other")
+}
+
+func TestFromCSVHeaderless(t *testing.T) {
+	path := writeTempFile(t, "messages_*.tsv", "greeting\tA greeting\tHello, world\nfarewell\t\tGoodbye\n")
+	messages, err := FromCSVWithOptions(path, CSVOptions{Comma: '\t'})
+	assert.NoError(t, err)
+	assert.Equal(t, []message.Message{
+		{ID: "farewell", Other: "Goodbye"},
+		{ID: "greeting", Description: "A greeting", Other: "Hello, world"},
+	}, messages)
+
+	messages, err = FromCSVWithOptions(path, CSVOptions{Comma: '\t', Columns: []string{"id", "", "other"}})
+	assert.NoError(t, err)
+	assert.Equal(t, []message.Message{
+		{ID: "farewell", Other: "Goodbye"},
+		{ID: "greeting", Other: "Hello, world"},
+	}, messages)
+}
+
+func TestFromCSVLocaleColumns(t *testing.T) {
+	path := writeTempFile(t, "messages_*.csv", "id,description,en,de.one,de\nitems,Item count,Items,Ein Artikel,{{.Count}} Artikel\n")
+
+	messages, err := FromCSV(path)
+	assert.NoError(t, err)
+	assert.Equal(t, []message.Message{{ID: "items", Description: "Item count", Other: "Items"}}, messages)
+
+	messages, err = FromCSVWithOptions(path, CSVOptions{Locale: "DE"})
+	assert.NoError(t, err)
+	assert.Equal(t, []message.Message{{ID: "items", Description: "Item count", One: "Ein Artikel", Other: "{{.Count}} Artikel"}}, messages)
+
+	_, err = FromCSVWithOptions(path, CSVOptions{Locale: "fr"})
+	assert.ErrorContains(t, err, `no column for locale "fr"`)
+
+	locales, err := CSVLocales(path, CSVOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"en", "de"}, locales)
+
+	path = writeTempFile(t, "messages_*.csv", "id,en,fr\nyes,Yes,Oui\nno,No,\n")
+	messages, err = FromCSVWithOptions(path, CSVOptions{Locale: "fr"})
+	assert.NoError(t, err)
+	assert.Equal(t, []message.Message{{ID: "yes", Other: "Oui"}}, messages)
+
+	_, err = CSVLocales(writeTempFile(t, "messages_*.csv", "id,description,other\n"), CSVOptions{})
+	assert.ErrorContains(t, err, "no locale columns in id, description, other")
+}
+
+func TestToCSV(t *testing.T) {
+	messages := []message.Message{
+		{ID: "items", Description: "Item count", One: "{{.Count}} item", Other: "{{.Count}} items"},
+		{ID: "quote", Description: "Says \"hi\", twice", Other: "line one\nline two"},
+	}
+	output, err := ToCSV(messages)
+	assert.NoError(t, err)
+
+	expected := "\ufeffid,description,one,other\r\n" +
+		"items,Item count,{{.Count}} item,{{.Count}} items\r\n" +
+		"quote,\"Says \"\"hi\"\", twice\",,\"line one\r\nline two\"\r\n"
+	assert.Equal(t, expected, output)
+
+	roundTrip, err := FromCSV(writeTempFile(t, "messages_*.csv", output))
+	assert.NoError(t, err)
+	assert.Equal(t, messages, roundTrip)
+
+	output, err = ToCSVWithOptions(messages, CSVOptions{Columns: []string{"id", "en", "de.one", "de"}, Locale: "de", Comma: '\t'})
+	assert.NoError(t, err)
+	expected = "\ufeffid\ten\tde.one\tde\r\n" +
+		"items\t\t{{.Count}} item\t{{.Count}} items\r\n" +
+		"quote\t\t\t\"line one\r\nline two\"\r\n"
+	assert.Equal(t, expected, output)
+}
@@ -2,23 +2,77 @@ package parser
 
 import (
 	"fmt"
+	"slices"
 	"sort"
+	"strconv"
+	"strings"
 
 	"github.com/s-nix/mk2i18n/message"
 )
 
+// FlattenOptions controls how nested data is turned into messages.
+// The zero value enables every detection.
+type FlattenOptions struct {
+	// DisablePluralDetection keeps plural sub-keys such as items.one and items.other,
+	// or i18next style items_one and items_other, as separate messages.
+	DisablePluralDetection bool
+
+	// DisableMessageDetection keeps maps shaped like go-i18n message objects,
+	// such as greeting: {description: ..., other: ...}, as separate greeting.description and greeting.other messages.
+	DisableMessageDetection bool
+}
+
+// messageObjectKeys lists the keys go-i18n reserves for message objects, in lower case.
+var messageObjectKeys = []string{"id", "description", "hash", "leftdelim", "rightdelim", "zero", "one", "two", "few", "many", "other"}
+
 // FlattenDataToMessages flattens a nested map[string]any structure into a slice of message.Message.
 // Each key in the nested structure is concatenated with its parent keys using dot notation.
 // The resulting messages are appended to the provided messages slice.
 // The messages are sorted by their ID before returning.
 func FlattenDataToMessages(data map[string]any, messages *[]message.Message, parent string) {
+	FlattenDataToMessagesWithOptions(data, messages, parent, FlattenOptions{})
+}
+
+// FlattenDataToMessagesWithOptions behaves like FlattenDataToMessages, using the given options.
+//
+// Unless plural detection is disabled, a map whose keys are all CLDR plural categories
+// (including "other") becomes a single plural message, and so do sibling keys sharing a
+// base name with i18next plural suffixes (items_one, items_other).
+//
+// Unless message detection is disabled, a map shaped like a go-i18n message object,
+// whose keys are all go-i18n message fields (description, hash, one, other, ...) and include "other",
+// becomes a single message with those fields filled in.
+func FlattenDataToMessagesWithOptions(data map[string]any, messages *[]message.Message, parent string, opts FlattenOptions) {
+	skip := map[string]bool{}
+	if !opts.DisablePluralDetection {
+		for base, keys := range findSuffixPluralGroups(data) {
+			key := base
+			if parent != "" {
+				key = parent + "." + base
+			}
+			msg := message.Message{ID: key}
+			for category, dataKey := range keys {
+				msg.SetPluralForm(category, fmt.Sprintf("%v", data[dataKey]))
+				skip[dataKey] = true
+			}
+			*messages = append(*messages, msg)
+		}
+	}
+
 	for key, value := range data {
+		if skip[key] {
+			continue
+		}
 		if parent != "" {
 			key = parent + "." + key
 		}
 		switch v := value.(type) {
 		case map[string]any:
-			FlattenDataToMessages(v, messages, key)
+			if msg, ok := detectMessage(key, v, opts); ok {
+				*messages = append(*messages, msg)
+				continue
+			}
+			FlattenDataToMessagesWithOptions(v, messages, key, opts)
 
 		case map[any]any:
 			convertedMap := make(map[string]any)
@@ -28,12 +82,16 @@ func FlattenDataToMessages(data map[string]any, messages *[]message.Message, par
 					convertedMap[strKey] = val
 				}
 			}
-			FlattenDataToMessages(convertedMap, messages, key)
+			if msg, ok := detectMessage(key, convertedMap, opts); ok {
+				*messages = append(*messages, msg)
+				continue
+			}
+			FlattenDataToMessagesWithOptions(convertedMap, messages, key, opts)
 
 		case []map[string]any:
 			for i, item := range v {
 				newKey := fmt.Sprintf("%s.%d", key, i)
-				FlattenDataToMessages(item, messages, newKey)
+				FlattenDataToMessagesWithOptions(item, messages, newKey, opts)
 			}
 
 		case []map[any]any:
@@ -46,7 +104,7 @@ func FlattenDataToMessages(data map[string]any, messages *[]message.Message, par
 						convertedMap[strKey] = val
 					}
 				}
-				FlattenDataToMessages(convertedMap, messages, newKey)
+				FlattenDataToMessagesWithOptions(convertedMap, messages, newKey, opts)
 			}
 
 		case []any:
@@ -54,7 +112,7 @@ func FlattenDataToMessages(data map[string]any, messages *[]message.Message, par
 			if ok {
 				for i, item := range mapSliceValue {
 					newKey := fmt.Sprintf("%s.%d", key, i)
-					FlattenDataToMessages(item, messages, newKey)
+					FlattenDataToMessagesWithOptions(item, messages, newKey, opts)
 				}
 				continue
 			}
@@ -62,7 +120,7 @@ func FlattenDataToMessages(data map[string]any, messages *[]message.Message, par
 				key := fmt.Sprintf("%s.%d", key, i)
 				valueMap, ok := item.(map[string]any)
 				if ok {
-					FlattenDataToMessages(valueMap, messages, key)
+					FlattenDataToMessagesWithOptions(valueMap, messages, key, opts)
 					continue
 				}
 				msg := message.Message{ID: key, Other: fmt.Sprintf("%v", item)}
@@ -78,3 +136,175 @@ func FlattenDataToMessages(data map[string]any, messages *[]message.Message, par
 		return (*messages)[i].ID < (*messages)[j].ID
 	})
 }
+
+// isScalar reports whether the value is a leaf value rather than a map or a slice.
+func isScalar(value any) bool {
+	switch value.(type) {
+	case map[string]any, map[any]any, []any, []map[string]any, []map[any]any:
+		return false
+	}
+	return true
+}
+
+// isPluralGroup reports whether every key of data is a CLDR plural category holding a leaf value.
+// The "other" category is required, as it is by go-i18n.
+func isPluralGroup(data map[string]any) bool {
+	if _, ok := data["other"]; !ok {
+		return false
+	}
+	for key, value := range data {
+		if !slices.Contains(message.PluralCategories, key) || !isScalar(value) {
+			return false
+		}
+	}
+	return true
+}
+
+// isMessageObject reports whether every key of data is a go-i18n message field holding a leaf value.
+// Keys are matched case-insensitively and the "other" field is required.
+func isMessageObject(data map[string]any) bool {
+	hasOther := false
+	for key, value := range data {
+		key = strings.ToLower(key)
+		if !slices.Contains(messageObjectKeys, key) || !isScalar(value) {
+			return false
+		}
+		if key == "other" {
+			hasOther = true
+		}
+	}
+	return hasOther
+}
+
+// detectMessage turns data into a single message when it is a plural group or a go-i18n message object
+// and the matching detection is enabled in opts.
+func detectMessage(id string, data map[string]any, opts FlattenOptions) (message.Message, bool) {
+	if !isMessageObject(data) {
+		return message.Message{}, false
+	}
+	if isPluralGroup(data) {
+		if opts.DisablePluralDetection {
+			return message.Message{}, false
+		}
+	} else if opts.DisableMessageDetection {
+		return message.Message{}, false
+	}
+
+	msg := message.Message{ID: id}
+	for key, value := range data {
+		str := fmt.Sprintf("%v", value)
+		switch strings.ToLower(key) {
+		case "description":
+			msg.Description = str
+		case "hash":
+			msg.Hash = str
+		case "leftdelim":
+			msg.LeftDelim = str
+		case "rightdelim":
+			msg.RightDelim = str
+		case "id":
+			// The message ID is taken from the key path.
+		default:
+			msg.SetPluralForm(strings.ToLower(key), str)
+		}
+	}
+	return msg, true
+}
+
+// findSuffixPluralGroups finds i18next style plural keys (items_one, items_other) among the leaf values of data.
+// It returns the keys of data grouped by their base name and plural category.
+// Only groups that contain an "other" key are returned.
+func findSuffixPluralGroups(data map[string]any) map[string]map[string]string {
+	groups := map[string]map[string]string{}
+	for key, value := range data {
+		if !isScalar(value) {
+			continue
+		}
+		index := strings.LastIndex(key, "_")
+		if index <= 0 {
+			continue
+		}
+		base, category := key[:index], key[index+1:]
+		if !slices.Contains(message.PluralCategories, category) {
+			continue
+		}
+		if groups[base] == nil {
+			groups[base] = map[string]string{}
+		}
+		groups[base][category] = key
+	}
+	for base, keys := range groups {
+		if _, ok := keys["other"]; !ok {
+			delete(groups, base)
+		}
+	}
+	return groups
+}
+
+// UnflattenMessages rebuilds the nested data that FlattenDataToMessages flattens into the given messages.
+//
+// The dot-separated segments of each message ID become nested keys, and the Other form becomes the value.
+// A plural message becomes a map of its plural forms, such as items: {one: ..., other: ...}.
+// Maps whose keys are exactly the indexes 0 to n-1 become slices again.
+// Descriptions and the other go-i18n fields are dropped. An ID that is both a message and the parent
+// of other IDs is an error, also when the message is plural, as in items and items.label.
+func UnflattenMessages(messages []message.Message) (map[string]any, error) {
+	root := map[string]any{}
+	for _, msg := range messages {
+		var value any = msg.Other
+		if msg.IsPlural() {
+			forms := pluralForms{}
+			for _, category := range message.PluralCategories {
+				if form := msg.PluralForm(category); form != "" || category == "other" {
+					forms[category] = form
+				}
+			}
+			value = forms
+		}
+		if err := nestValue(root, strings.Split(msg.ID, "."), value); err != nil {
+			return nil, fmt.Errorf("message %q: %w", msg.ID, err)
+		}
+	}
+	return restoreSlices(root).(map[string]any), nil
+}
+
+// pluralForms holds the plural forms of a message while UnflattenMessages nests the messages.
+// Its own type keeps nestValue from taking it for a parent of other keys, so that an ID below a plural message,
+// such as items.label next to the plural items, is an error whichever comes first.
+type pluralForms map[string]any
+
+// restoreSlices replaces the maps in value whose keys are exactly the indexes 0 to n-1 with slices,
+// and the plural forms with plain maps. The root map is never replaced.
+func restoreSlices(value any) any {
+	if forms, ok := value.(pluralForms); ok {
+		return map[string]any(forms)
+	}
+	data, ok := value.(map[string]any)
+	if !ok {
+		return value
+	}
+	for key, child := range data {
+		data[key] = restoreSlices(child)
+		if items, ok := indexedSlice(data[key]); ok {
+			data[key] = items
+		}
+	}
+	return data
+}
+
+// indexedSlice returns the values of a map keyed by the indexes 0 to n-1 as a slice.
+func indexedSlice(value any) ([]any, bool) {
+	data, ok := value.(map[string]any)
+	if !ok || len(data) == 0 {
+		return nil, false
+	}
+	items := make([]any, len(data))
+	for i := range items {
+		item, ok := data[strconv.Itoa(i)]
+		if !ok {
+			return nil, false
+		}
+		items[i] = item
+	}
+	return items, true
+}
@@ -34,3 +34,221 @@ func TestFlattenDataToMessages(t *testing.T) {
 
 	assert.Equal(t, expectedMessages, messages)
 }
+
+func TestFlattenDataToMessages_PluralGroups(t *testing.T) {
+	data := map[string]any{
+		"items": map[string]any{
+			"one":   "{{.Count}} item",
+			"other": "{{.Count}} items",
+		},
+		"cart": map[any]any{
+			"few":   "{{.Count}} products",
+			"other": "{{.Count}} product",
+			"zero":  "empty",
+		},
+		"ordinals": map[string]any{
+			"one": "first",
+			"two": "second",
+		},
+		"apples_one":   "one apple",
+		"apples_other": "{{.Count}} apples",
+		"pears_one":    "one pear",
+	}
+
+	var messages []message.Message
+	FlattenDataToMessages(data, &messages, "")
+
+	expectedMessages := []message.Message{
+		{ID: "apples", One: "one apple", Other: "{{.Count}} apples"},
+		{ID: "cart", Zero: "empty", Few: "{{.Count}} products", Other: "{{.Count}} product"},
+		{ID: "items", One: "{{.Count}} item", Other: "{{.Count}} items"},
+		{ID: "ordinals.one", Other: "first"},
+		{ID: "ordinals.two", Other: "second"},
+		{ID: "pears_one", Other: "one pear"},
+	}
+
+	assert.Equal(t, expectedMessages, messages)
+}
+
+func TestFlattenDataToMessages_NestedSuffixPlurals(t *testing.T) {
+	data := map[string]any{
+		"shop": map[string]any{
+			"items_one":   "one item",
+			"items_other": "many items",
+			"title":       "Shop",
+		},
+	}
+
+	var messages []message.Message
+	FlattenDataToMessages(data, &messages, "")
+
+	expectedMessages := []message.Message{
+		{ID: "shop.items", One: "one item", Other: "many items"},
+		{ID: "shop.title", Other: "Shop"},
+	}
+
+	assert.Equal(t, expectedMessages, messages)
+}
+
+func TestFlattenDataToMessagesWithOptions_DisablePluralDetection(t *testing.T) {
+	data := map[string]any{
+		"items": map[string]any{
+			"one":   "{{.Count}} item",
+			"other": "{{.Count}} items",
+		},
+		"apples_one":   "one apple",
+		"apples_other": "{{.Count}} apples",
+	}
+
+	var messages []message.Message
+	FlattenDataToMessagesWithOptions(data, &messages, "", FlattenOptions{DisablePluralDetection: true})
+
+	expectedMessages := []message.Message{
+		{ID: "apples_one", Other: "one apple"},
+		{ID: "apples_other", Other: "{{.Count}} apples"},
+		{ID: "items.one", Other: "{{.Count}} item"},
+		{ID: "items.other", Other: "{{.Count}} items"},
+	}
+
+	assert.Equal(t, expectedMessages, messages)
+}
+
+func TestFlattenDataToMessages_MessageObjects(t *testing.T) {
+	data := map[string]any{
+		"greeting": map[string]any{
+			"description": "A greeting message",
+			"other":       "Hello",
+		},
+		"items": map[string]any{
+			"id":          "ignored",
+			"hash":        "sha1-abc",
+			"description": "Number of items",
+			"leftDelim":   "<<",
+			"rightDelim":  ">>",
+			"one":         "<<.Count>> item",
+			"Other":       "<<.Count>> items",
+		},
+		"profile": map[string]any{
+			"description": "Profile page",
+			"title":       "Profile",
+		},
+		"hint": map[string]any{
+			"description": "No other field",
+		},
+	}
+
+	var messages []message.Message
+	FlattenDataToMessages(data, &messages, "")
+
+	expectedMessages := []message.Message{
+		{ID: "greeting", Description: "A greeting message", Other: "Hello"},
+		{ID: "hint.description", Other: "No other field"},
+		{
+			ID:          "items",
+			Hash:        "sha1-abc",
+			Description: "Number of items",
+			LeftDelim:   "<<",
+			RightDelim:  ">>",
+			One:         "<<.Count>> item",
+			Other:       "<<.Count>> items",
+		},
+		{ID: "profile.description", Other: "Profile page"},
+		{ID: "profile.title", Other: "Profile"},
+	}
+
+	assert.Equal(t, expectedMessages, messages)
+}
+
+func TestFlattenDataToMessagesWithOptions_DisableMessageDetection(t *testing.T) {
+	data := map[string]any{
+		"greeting": map[string]any{
+			"description": "A greeting message",
+			"other":       "Hello",
+		},
+		"items": map[string]any{
+			"one":   "{{.Count}} item",
+			"other": "{{.Count}} items",
+		},
+	}
+
+	var messages []message.Message
+	FlattenDataToMessagesWithOptions(data, &messages, "", FlattenOptions{DisableMessageDetection: true})
+
+	expectedMessages := []message.Message{
+		{ID: "greeting.description", Other: "A greeting message"},
+		{ID: "greeting.other", Other: "Hello"},
+		{ID: "items", One: "{{.Count}} item", Other: "{{.Count}} items"},
+	}
+
+	assert.Equal(t, expectedMessages, messages)
+}
+
+func TestUnflattenMessages(t *testing.T) {
+	data := map[string]any{
+		"simple_key": "simple_value",
+		"nested": map[string]any{
+			"inner_key":   "inner_value",
+			"inner_array": []any{"value1", "value2"},
+		},
+		"array_of_maps": []any{
+			map[string]any{"map_key1": "map_value1"},
+			map[string]any{"map_key2": "map_value2"},
+		},
+		"items": map[string]any{
+			"one":   "{{.Count}} item",
+			"other": "{{.Count}} items",
+		},
+		"sparse": map[string]any{"1": "one", "2": "two"},
+	}
+
+	var messages []message.Message
+	FlattenDataToMessages(data, &messages, "")
+	unflattened, err := UnflattenMessages(messages)
+	assert.NoError(t, err)
+	assert.Equal(t, data, unflattened)
+
+	_, err = UnflattenMessages([]message.Message{{ID: "a", Other: "1"}, {ID: "a.b", Other: "2"}})
+	assert.ErrorContains(t, err, `message "a.b": key "a" is both a value and a parent of other keys`)
+
+	plural := message.Message{ID: "items", One: "One item", Other: "{{.Count}} items"}
+	label := message.Message{ID: "items.label", Other: "Items"}
+	_, err = UnflattenMessages([]message.Message{plural, label})
+	assert.ErrorContains(t, err, `message "items.label": key "items" is both a value and a parent of other keys`)
+	_, err = UnflattenMessages([]message.Message{label, plural})
+	assert.ErrorContains(t, err, `message "items": key "items" is both a value and a parent of other keys`)
+}
+
+func TestToNested(t *testing.T) {
+	messages := []message.Message{
+		{ID: "menu.items.0", Description: "First item", Other: "Open"},
+		{ID: "menu.items.1", Other: "Close"},
+		{ID: "cart.count", One: "{{.Count}} item", Other: "{{.Count}} items"},
+	}
+
+	output, err := ToNestedJSON(messages)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"cart": {"count": {"one": "{{.Count}} item", "other": "{{.Count}} items"}}, "menu": {"items": ["Open", "Close"]}}`, output)
+
+	output, err = ToNestedYAML(messages)
+	assert.NoError(t, err)
+	assert.Equal(t, `cart:
+  count:
+    one: '{{.Count}} item'
+    other: '{{.Count}} items'
+menu:
+  items:
+    - Open
+    - Close
+`, output)
+
+	output, err = ToNestedTOML(messages)
+	assert.NoError(t, err)
+	assert.Equal(t, `[cart]
+  [cart.count]
+    one = "{{.Count}} item"
+    other = "{{.Count}} items"
+
+[menu]
+  items = ["Open", "Close"]
+`, output)
+}
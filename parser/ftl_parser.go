@@ -0,0 +1,683 @@
+package parser
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/s-nix/mk2i18n/message"
+)
+
+// ftlEntry is a message, message attribute or term of a Fluent resource.
+type ftlEntry struct {
+	id          string
+	description string
+	elements    []ftlElement
+	term        bool
+	line        int
+}
+
+// ftlElement is a part of a Fluent pattern: text, a reference to a message or term, or a select expression.
+type ftlElement struct {
+	text      string
+	reference string
+	selection *ftlSelect
+	line      int
+}
+
+// ftlSelect is a select expression on plural categories.
+type ftlSelect struct {
+	variants      map[string][]ftlElement
+	exact         map[string][]ftlElement
+	defaultPlural []ftlElement
+}
+
+// ftlIndent marks the start of a continuation line of a pattern before the common indentation is removed.
+type ftlIndent struct {
+	index  int
+	indent int
+	blank  bool
+}
+
+// ftlParser reads the entries of a Fluent resource.
+type ftlParser struct {
+	path string
+	src  string
+	pos  int
+}
+
+// FromFluent reads a Project Fluent (.ftl) resource into messages.
+//
+// Every message becomes a message with its identifier as ID, and every attribute a message with the ID
+// msg.attr. The # comment directly above a message becomes the Description of the message and its attributes;
+// group (##) and resource (###) comments are ignored. Multiline patterns are joined with newlines after
+// removing their common indentation. Terms are not messages themselves: references to terms, such as
+// { -brand }, and to other messages are replaced by their value. Variables such as { $name } become template
+// fields such as {{.name}}, and so does the variable of NUMBER and DATETIME calls. A select expression on
+// plural categories, such as { $count -> [one] ... *[other] ... }, becomes the plural forms, with the text around
+// it added to every form and [0], [1] and [2] used as zero, one and two when those categories are missing.
+// Constructs without an equivalent, such as selects on other keys, parameterized terms, other functions and
+// nested selects, are reported as errors with their line number.
+func FromFluent(inputPath string) ([]message.Message, error) {
+	content, err := os.ReadFile(inputPath)
+	if err != nil {
+		return nil, err
+	}
+	src := strings.TrimPrefix(string(content), "\ufeff")
+	p := &ftlParser{path: inputPath, src: strings.ReplaceAll(src, "\r\n", "\n")}
+	entries, err := p.parseResource()
+	if err != nil {
+		return nil, err
+	}
+
+	byID := map[string]*ftlEntry{}
+	for i := range entries {
+		byID[entries[i].id] = &entries[i]
+	}
+
+	var messages []message.Message
+	for _, entry := range entries {
+		if entry.term || len(entry.elements) == 0 {
+			continue
+		}
+		msg, err := p.render(entry.elements, byID, []string{entry.id})
+		if err != nil {
+			return nil, err
+		}
+		msg.ID = entry.id
+		msg.Description = entry.description
+		messages = append(messages, msg)
+	}
+
+	if len(messages) == 0 {
+		return nil, nil
+	}
+	sort.Slice(messages, func(i, j int) bool {
+		return messages[i].ID < messages[j].ID
+	})
+	return messages, nil
+}
+
+// render resolves the references and select expression of a pattern into a message.
+// The chain holds the IDs being rendered, to report references that loop back on themselves.
+func (p *ftlParser) render(elements []ftlElement, byID map[string]*ftlEntry, chain []string) (message.Message, error) {
+	var msg message.Message
+	var prefix, suffix strings.Builder
+	var selection *ftlSelect
+	for _, element := range elements {
+		text := element.text
+		switch {
+		case element.reference != "":
+			target, ok := byID[element.reference]
+			if !ok {
+				return msg, p.errorf(element.line, "unknown reference %s", element.reference)
+			}
+			for _, id := range chain {
+				if id == element.reference {
+					return msg, p.errorf(element.line, "%s references itself through %s", chain[0], strings.Join(append(chain, id), " -> "))
+				}
+			}
+			resolved, err := p.render(target.elements, byID, append(chain, element.reference))
+			if err != nil {
+				return msg, err
+			}
+			if resolved.IsPlural() {
+				return msg, p.errorf(element.line, "reference to %s, which has plural variants, is not supported", element.reference)
+			}
+			text = resolved.Other
+		case element.selection != nil:
+			if selection != nil {
+				return msg, p.errorf(element.line, "more than one select expression in a pattern is not supported")
+			}
+			selection = element.selection
+			continue
+		}
+		if selection == nil {
+			prefix.WriteString(text)
+		} else {
+			suffix.WriteString(text)
+		}
+	}
+	if selection == nil {
+		msg.Other = prefix.String()
+		return msg, nil
+	}
+
+	// variant renders the pattern of one variant, which must not hold another select expression.
+	variant := func(elements []ftlElement) (string, error) {
+		for _, element := range elements {
+			if element.selection != nil {
+				return "", p.errorf(element.line, "nested select expressions are not supported")
+			}
+		}
+		resolved, err := p.render(elements, byID, chain)
+		if err != nil {
+			return "", err
+		}
+		return prefix.String() + resolved.Other + suffix.String(), nil
+	}
+	for category, elements := range selection.variants {
+		form, err := variant(elements)
+		if err != nil {
+			return msg, err
+		}
+		msg.SetPluralForm(category, form)
+	}
+	for category, elements := range selection.exact {
+		if msg.PluralForm(category) == "" {
+			form, err := variant(elements)
+			if err != nil {
+				return msg, err
+			}
+			msg.SetPluralForm(category, form)
+		}
+	}
+	if msg.Other == "" {
+		form, err := variant(selection.defaultPlural)
+		if err != nil {
+			return msg, err
+		}
+		msg.Other = form
+	}
+	return msg, nil
+}
+
+// parseResource parses the comments, messages and terms of the resource.
+func (p *ftlParser) parseResource() ([]ftlEntry, error) {
+	var entries []ftlEntry
+	var comment []string
+	seen := map[string]int{}
+	add := func(entry ftlEntry) error {
+		if line, ok := seen[entry.id]; ok {
+			return p.errorf(entry.line, "duplicate message ID %q, first defined on line %d", entry.id, line)
+		}
+		seen[entry.id] = entry.line
+		entries = append(entries, entry)
+		return nil
+	}
+
+	for p.pos < len(p.src) {
+		line := p.line()
+		switch c := p.src[p.pos]; {
+		case c == '\n':
+			// A blank line detaches the comment above it from the next message.
+			comment = nil
+			p.pos++
+		case c == ' ':
+			p.skipInline()
+			if p.pos < len(p.src) && p.src[p.pos] != '\n' {
+				return nil, p.errorf(line, "unexpected indented line")
+			}
+		case c == '#':
+			text := p.src[p.pos:]
+			if end := strings.IndexByte(text, '\n'); end >= 0 {
+				text = text[:end]
+			}
+			p.pos += len(text)
+			level := len(text) - len(strings.TrimLeft(text, "#"))
+			text = text[level:]
+			if text != "" && !strings.HasPrefix(text, " ") || level > 3 {
+				return nil, p.errorf(line, "invalid comment %q", strings.Repeat("#", level)+text)
+			}
+			if level == 1 {
+				comment = append(comment, strings.TrimPrefix(text, " "))
+			} else {
+				comment = nil
+			}
+			if p.pos < len(p.src) {
+				p.pos++
+			}
+		case c == '-' || isFTLIdentifierStart(c):
+			term := c == '-'
+			if term {
+				p.pos++
+			}
+			id := p.identifier()
+			if id == "" {
+				return nil, p.errorf(line, "expected a term identifier")
+			}
+			if term {
+				id = "-" + id
+			}
+			entry := ftlEntry{id: id, description: strings.Join(comment, "\n"), term: term, line: line}
+			comment = nil
+			attributes, err := p.parseEntry(&entry)
+			if err != nil {
+				return nil, err
+			}
+			if len(entry.elements) == 0 && (term || len(attributes) == 0) {
+				return nil, p.errorf(line, "%s has no value", id)
+			}
+			if err := add(entry); err != nil {
+				return nil, err
+			}
+			for _, attribute := range attributes {
+				if err := add(attribute); err != nil {
+					return nil, err
+				}
+			}
+		default:
+			return nil, p.errorf(line, "expected a message, term or comment")
+		}
+	}
+	return entries, nil
+}
+
+// parseEntry parses the value and attributes of a message or term, starting after its identifier.
+func (p *ftlParser) parseEntry(entry *ftlEntry) ([]ftlEntry, error) {
+	p.skipInline()
+	if p.pos >= len(p.src) || p.src[p.pos] != '=' {
+		return nil, p.errorf(p.line(), "expected = after %s", entry.id)
+	}
+	p.pos++
+	elements, err := p.parsePattern()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.src) && p.src[p.pos] == '}' {
+		return nil, p.errorf(p.line(), "unbalanced closing brace")
+	}
+	entry.elements = elements
+
+	var attributes []ftlEntry
+	for {
+		next := p.pos
+		for next < len(p.src) && (p.src[next] == '\n' || p.src[next] == ' ') {
+			next++
+		}
+		if next >= len(p.src) || p.src[next] != '.' || next == p.pos || p.src[next-1] != ' ' {
+			return attributes, nil
+		}
+		p.pos = next + 1
+		line := p.line()
+		name := p.identifier()
+		if name == "" {
+			return nil, p.errorf(line, "expected an attribute name")
+		}
+		p.skipInline()
+		if p.pos >= len(p.src) || p.src[p.pos] != '=' {
+			return nil, p.errorf(line, "expected = after attribute %s", name)
+		}
+		p.pos++
+		elements, err := p.parsePattern()
+		if err != nil {
+			return nil, err
+		}
+		if p.pos < len(p.src) && p.src[p.pos] == '}' {
+			return nil, p.errorf(p.line(), "unbalanced closing brace")
+		}
+		if len(elements) == 0 {
+			return nil, p.errorf(line, "attribute %s of %s has no value", name, entry.id)
+		}
+		attributes = append(attributes, ftlEntry{
+			id:          entry.id + "." + name,
+			description: entry.description,
+			elements:    elements,
+			term:        entry.term,
+			line:        line,
+		})
+	}
+}
+
+// parsePattern parses text and placeables up to the end of the pattern, removing the common indentation
+// of its continuation lines. It stops before the newline ending the pattern, or at a closing brace.
+func (p *ftlParser) parsePattern() ([]ftlElement, error) {
+	p.skipInline()
+	var elements []ftlElement
+	var indents []ftlIndent
+	var text strings.Builder
+	flush := func() {
+		if text.Len() > 0 {
+			elements = append(elements, ftlElement{text: text.String()})
+			text.Reset()
+		}
+	}
+
+parse:
+	for p.pos < len(p.src) {
+		switch c := p.src[p.pos]; c {
+		case '{':
+			flush()
+			element, err := p.parsePlaceable()
+			if err != nil {
+				return nil, err
+			}
+			elements = append(elements, element)
+		case '}':
+			break parse
+		case '\n':
+			// The pattern continues on the next non-blank line when it is indented and does not start
+			// an attribute, a variant or the end of a select expression.
+			next, blanks := p.pos+1, 0
+			for {
+				start := next
+				for next < len(p.src) && p.src[next] == ' ' {
+					next++
+				}
+				if next < len(p.src) && p.src[next] == '\n' {
+					blanks++
+					next++
+					continue
+				}
+				if next == start || next >= len(p.src) || strings.IndexByte("[*.}", p.src[next]) >= 0 {
+					break parse
+				}
+				flush()
+				for ; blanks > 0; blanks-- {
+					indents = append(indents, ftlIndent{index: len(elements), blank: true})
+					elements = append(elements, ftlElement{})
+				}
+				indents = append(indents, ftlIndent{index: len(elements), indent: next - start})
+				elements = append(elements, ftlElement{})
+				p.pos = next
+				break
+			}
+		default:
+			text.WriteByte(c)
+			p.pos++
+		}
+	}
+	flush()
+
+	common := -1
+	for _, indent := range indents {
+		if !indent.blank && (common < 0 || indent.indent < common) {
+			common = indent.indent
+		}
+	}
+	for i, indent := range indents {
+		text := "\n"
+		if !indent.blank {
+			text += strings.Repeat(" ", indent.indent-common)
+		}
+		if indent.index == i {
+			// Only line starts come before this one, so the value starts on a line after the = or variant key,
+			// and the newlines before its first line are not part of it.
+			text = text[1:]
+		}
+		elements[indent.index].text = text
+	}
+
+	var merged []ftlElement
+	for _, element := range elements {
+		last := len(merged) - 1
+		if element.reference == "" && element.selection == nil {
+			if element.text == "" {
+				continue
+			}
+			if last >= 0 && merged[last].reference == "" && merged[last].selection == nil {
+				merged[last].text += element.text
+				continue
+			}
+		}
+		merged = append(merged, element)
+	}
+	if last := len(merged) - 1; last >= 0 && merged[last].reference == "" && merged[last].selection == nil {
+		merged[last].text = strings.TrimRight(merged[last].text, " \n")
+		if merged[last].text == "" {
+			merged = merged[:last]
+		}
+	}
+	return merged, nil
+}
+
+// parsePlaceable parses a placeable, starting at its opening brace.
+func (p *ftlParser) parsePlaceable() (ftlElement, error) {
+	line := p.line()
+	p.pos++
+	p.skipBlank()
+	element, variable, err := p.parseInlineExpression()
+	if err != nil {
+		return element, err
+	}
+	p.skipBlank()
+	if strings.HasPrefix(p.src[p.pos:], "->") {
+		if variable == "" {
+			return element, p.errorf(line, "select expressions are only supported on variables")
+		}
+		p.pos += 2
+		selection, err := p.parseVariants(line)
+		if err != nil {
+			return element, err
+		}
+		element = ftlElement{selection: selection, line: line}
+		p.skipBlank()
+	}
+	if p.pos >= len(p.src) || p.src[p.pos] != '}' {
+		return element, p.errorf(p.line(), "expected } to close the placeable opened on line %d", line)
+	}
+	p.pos++
+	return element, nil
+}
+
+// parseInlineExpression parses a literal, variable, function call, reference or nested placeable.
+// It returns the name of the variable for a variable or a NUMBER or DATETIME call on one.
+func (p *ftlParser) parseInlineExpression() (ftlElement, string, error) {
+	line := p.line()
+	if p.pos >= len(p.src) {
+		return ftlElement{}, "", p.errorf(line, "unterminated placeable")
+	}
+	switch c := p.src[p.pos]; {
+	case c == '"':
+		text, err := p.parseStringLiteral()
+		return ftlElement{text: text}, "", err
+	case c >= '0' && c <= '9' || c == '-' && p.pos+1 < len(p.src) && p.src[p.pos+1] >= '0' && p.src[p.pos+1] <= '9':
+		start := p.pos
+		p.pos++
+		for p.pos < len(p.src) && (p.src[p.pos] >= '0' && p.src[p.pos] <= '9' || p.src[p.pos] == '.') {
+			p.pos++
+		}
+		return ftlElement{text: p.src[start:p.pos]}, "", nil
+	case c == '$':
+		p.pos++
+		name := p.identifier()
+		if name == "" {
+			return ftlElement{}, "", p.errorf(line, "expected a variable name after $")
+		}
+		return ftlElement{text: "{{." + name + "}}"}, name, nil
+	case c == '-':
+		p.pos++
+		name := p.identifier()
+		if name == "" {
+			return ftlElement{}, "", p.errorf(line, "expected a term name after -")
+		}
+		if p.pos < len(p.src) && p.src[p.pos] == '.' {
+			p.pos++
+			return ftlElement{}, "", p.errorf(line, "select expressions on term attribute -%s.%s are not supported", name, p.identifier())
+		}
+		if p.skipInline(); p.pos < len(p.src) && p.src[p.pos] == '(' {
+			return ftlElement{}, "", p.errorf(line, "parameterized term -%s is not supported", name)
+		}
+		return ftlElement{reference: "-" + name, line: line}, "", nil
+	case c == '{':
+		element, err := p.parsePlaceable()
+		return element, "", err
+	case isFTLIdentifierStart(c):
+		name := p.identifier()
+		if p.pos < len(p.src) && p.src[p.pos] == '(' {
+			return p.parseFunction(name, line)
+		}
+		if p.pos < len(p.src) && p.src[p.pos] == '.' {
+			p.pos++
+			attribute := p.identifier()
+			if attribute == "" {
+				return ftlElement{}, "", p.errorf(line, "expected an attribute name after %s.", name)
+			}
+			name += "." + attribute
+		}
+		return ftlElement{reference: name, line: line}, "", nil
+	default:
+		return ftlElement{}, "", p.errorf(line, "expected an expression in the placeable")
+	}
+}
+
+// parseFunction parses a NUMBER or DATETIME call, starting at its opening parenthesis.
+// Only calls on a variable are supported; their options are dropped.
+func (p *ftlParser) parseFunction(name string, line int) (ftlElement, string, error) {
+	if name != "NUMBER" && name != "DATETIME" {
+		return ftlElement{}, "", p.errorf(line, "function %s is not supported", name)
+	}
+	p.pos++
+	p.skipBlank()
+	if p.pos >= len(p.src) || p.src[p.pos] != '$' {
+		return ftlElement{}, "", p.errorf(line, "%s is only supported on a variable", name)
+	}
+	element, variable, err := p.parseInlineExpression()
+	if err != nil {
+		return element, "", err
+	}
+	end := strings.IndexByte(p.src[p.pos:], ')')
+	if end < 0 {
+		return element, "", p.errorf(line, "unterminated call of %s", name)
+	}
+	p.pos += end + 1
+	return element, variable, nil
+}
+
+// parseVariants parses the variants of a select expression up to its closing brace,
+// mapping their keys to plural categories.
+func (p *ftlParser) parseVariants(line int) (*ftlSelect, error) {
+	selection := &ftlSelect{variants: map[string][]ftlElement{}, exact: map[string][]ftlElement{}}
+	hasDefault := false
+	for {
+		p.skipBlank()
+		if p.pos >= len(p.src) {
+			return nil, p.errorf(line, "unterminated select expression")
+		}
+		if p.src[p.pos] == '}' {
+			break
+		}
+		variantLine := p.line()
+		isDefault := p.src[p.pos] == '*'
+		if isDefault {
+			p.pos++
+		}
+		if p.pos >= len(p.src) || p.src[p.pos] != '[' {
+			return nil, p.errorf(variantLine, "expected a variant key")
+		}
+		end := strings.IndexByte(p.src[p.pos:], ']')
+		if end < 0 {
+			return nil, p.errorf(variantLine, "unterminated variant key")
+		}
+		key := strings.TrimSpace(p.src[p.pos+1 : p.pos+end])
+		p.pos += end + 1
+		elements, err := p.parsePattern()
+		if err != nil {
+			return nil, err
+		}
+
+		if isDefault {
+			if hasDefault {
+				return nil, p.errorf(variantLine, "select expression has more than one default variant")
+			}
+			hasDefault = true
+			selection.defaultPlural = elements
+		}
+		if category, ok := icuExactPlurals["="+key]; ok {
+			selection.exact[category] = elements
+			continue
+		}
+		isCategory := false
+		for _, category := range message.PluralCategories {
+			isCategory = isCategory || category == key
+		}
+		if !isCategory {
+			return nil, p.errorf(variantLine, "variant key [%s] is not a plural category", key)
+		}
+		selection.variants[key] = elements
+	}
+	if !hasDefault {
+		return nil, p.errorf(line, "select expression has no default variant")
+	}
+	return selection, nil
+}
+
+// parseStringLiteral parses a quoted string literal, starting at its opening quote.
+func (p *ftlParser) parseStringLiteral() (string, error) {
+	line := p.line()
+	var sb strings.Builder
+	for p.pos++; p.pos < len(p.src); p.pos++ {
+		switch c := p.src[p.pos]; c {
+		case '"':
+			p.pos++
+			return sb.String(), nil
+		case '\n':
+			return "", p.errorf(line, "unterminated string literal")
+		case '\\':
+			p.pos++
+			if p.pos >= len(p.src) {
+				return "", p.errorf(line, "unterminated string literal")
+			}
+			switch escape := p.src[p.pos]; escape {
+			case '"', '\\':
+				sb.WriteByte(escape)
+			case 'u', 'U':
+				size := 4
+				if escape == 'U' {
+					size = 6
+				}
+				if p.pos+size >= len(p.src) {
+					return "", p.errorf(line, "invalid unicode escape")
+				}
+				code, err := strconv.ParseUint(p.src[p.pos+1:p.pos+1+size], 16, 32)
+				if err != nil {
+					return "", p.errorf(line, "invalid unicode escape \\%c%s", escape, p.src[p.pos+1:p.pos+1+size])
+				}
+				sb.WriteRune(rune(code))
+				p.pos += size
+			default:
+				return "", p.errorf(line, "unknown escape sequence \\%c", escape)
+			}
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	return "", p.errorf(line, "unterminated string literal")
+}
+
+// identifier reads an identifier at the current position, returning an empty string when there is none.
+func (p *ftlParser) identifier() string {
+	start := p.pos
+	if p.pos < len(p.src) && isFTLIdentifierStart(p.src[p.pos]) {
+		p.pos++
+		for p.pos < len(p.src) {
+			c := p.src[p.pos]
+			if !isFTLIdentifierStart(c) && (c < '0' || c > '9') && c != '_' && c != '-' {
+				break
+			}
+			p.pos++
+		}
+	}
+	return p.src[start:p.pos]
+}
+
+// skipInline skips spaces.
+func (p *ftlParser) skipInline() {
+	for p.pos < len(p.src) && p.src[p.pos] == ' ' {
+		p.pos++
+	}
+}
+
+// skipBlank skips spaces and newlines.
+func (p *ftlParser) skipBlank() {
+	for p.pos < len(p.src) && (p.src[p.pos] == ' ' || p.src[p.pos] == '\n') {
+		p.pos++
+	}
+}
+
+// line returns the line number of the current position.
+func (p *ftlParser) line() int {
+	return strings.Count(p.src[:p.pos], "\n") + 1
+}
+
+// errorf returns an error prefixed with the path and the given line.
+func (p *ftlParser) errorf(line int, format string, args ...any) error {
+	return fmt.Errorf("%s:%d: %s", p.path, line, fmt.Sprintf(format, args...))
+}
+
+// isFTLIdentifierStart reports whether c can start a Fluent identifier.
+func isFTLIdentifierStart(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
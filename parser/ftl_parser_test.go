@@ -0,0 +1,86 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/s-nix/mk2i18n/message"
+	"github.com/stretchr/testify/assert"
+)
+
+const ftlContent = `### Resource comment
+
+## Group comment
+
+-brand = Firefox
+
+# Shown on the start page
+# after login
+welcome = Welcome to { -brand }, { $user }!
+
+emails =
+    { $count ->
+        [0] No new emails
+        [one] One new email
+       *[other] { NUMBER($count) } new emails
+    }
+
+files = You have { $count ->
+    [one] one file
+   *[other] { $count } files
+} in { folder }.
+
+folder = your folder
+
+about =
+    First line
+      indented line
+
+    after a blank line
+
+# Login form
+login-input = Predefined value
+    .placeholder = email@example.com
+    .aria-label = Login input value
+
+literal = { "{" }braces{ "}" } and { "é" }
+`
+
+func TestFromFluent(t *testing.T) {
+	messages, err := FromFluent(writeTempFile(t, "messages_*.ftl", ftlContent))
+	assert.NoError(t, err)
+
+	expectedMessages := []message.Message{
+		{ID: "about", Other: "First line\n  indented line\n\nafter a blank line"},
+		{ID: "emails", Zero: "No new emails", One: "One new email", Other: "{{.count}} new emails"},
+		{ID: "files", One: "You have one file in your folder.", Other: "You have {{.count}} files in your folder."},
+		{ID: "folder", Other: "your folder"},
+		{ID: "literal", Other: "{braces} and é"},
+		{ID: "login-input", Description: "Login form", Other: "Predefined value"},
+		{ID: "login-input.aria-label", Description: "Login form", Other: "Login input value"},
+		{ID: "login-input.placeholder", Description: "Login form", Other: "email@example.com"},
+		{ID: "welcome", Description: "Shown on the start page\nafter login", Other: "Welcome to Firefox, {{.user}}!"},
+	}
+	assert.Equal(t, expectedMessages, messages)
+}
+
+func TestFromFluentUnsupported(t *testing.T) {
+	tests := []struct {
+		content string
+		err     string
+	}{
+		{"a = x\n\ngender = { $g ->\n    [male] his\n   *[other] their\n}\n", ":4: variant key [male] is not a plural category"},
+		{"a = { -brand(case: \"nominative\") }\n-brand = B\n", ":1: parameterized term -brand is not supported"},
+		{"a = { PLATFORM() }\n", ":1: function PLATFORM is not supported"},
+		{"a = { b }\n", ":1: unknown reference b"},
+		{"a = { b }\nb = { a }\n", ":2: a references itself through a -> b -> a"},
+		{"a = x\na = y\n", `:2: duplicate message ID "a", first defined on line 1`},
+		{"a = { -t.gender ->\n   *[other] x\n}\n", ":1: select expressions on term attribute -t.gender are not supported"},
+		{"a = { $n ->\n    [one] x\n}\n", ":1: select expression has no default variant"},
+		{"a =\n", ":1: a has no value"},
+		{"  indented = x\n", ":1: unexpected indented line"},
+	}
+	for _, test := range tests {
+		_, err := FromFluent(writeTempFile(t, "messages_*.ftl", test.content))
+		assert.ErrorContains(t, err, test.err, test.content)
+	}
+}
@@ -0,0 +1,22 @@
+package parser
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// writeTempFile writes content to a new temporary file named after pattern, as by os.CreateTemp,
+// and returns its path. The file is removed when the test finishes.
+func writeTempFile(t *testing.T, pattern string, content string) string {
+	tmpFile, err := os.CreateTemp("", pattern)
+	assert.NoError(t, err)
+	_, err = tmpFile.WriteString(content)
+	assert.NoError(t, err)
+	assert.NoError(t, tmpFile.Close())
+	t.Cleanup(func() {
+		_ = os.Remove(tmpFile.Name())
+	})
+	return tmpFile.Name()
+}
@@ -0,0 +1,201 @@
+package parser
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/s-nix/mk2i18n/message"
+)
+
+// I18nextOptions configures how i18next JSON files are read and written.
+type I18nextOptions struct {
+	// ContextSeparator joins a message ID and its i18next context, so that friend_male becomes friend#male.
+	// Defaults to "#".
+	ContextSeparator string
+}
+
+var (
+	// rI18nextInterpolation matches i18next interpolations, such as {{name}}, {{- html}} and {{price, currency}}.
+	rI18nextInterpolation = regexp.MustCompile(`\{\{-?\s*([\w.]+)\s*(?:,[^}]*)?\}\}`)
+
+	// rI18nextNesting matches i18next nesting, such as $t(common.ok) or $t(items, {"count": 2}).
+	rI18nextNesting = regexp.MustCompile(`\$t\(\s*([^,)]+?)\s*(?:,[^)]*)?\)`)
+
+	// rTemplatePath matches template fields with a dotted path, such as {{.user.name}} or {{printf "%d" .cart.count}}.
+	rTemplatePath = regexp.MustCompile(`\{\{\s*(?:printf\s+"[^"]*"\s+)?\.([\w.]+)\s*\}\}`)
+)
+
+// FromI18next reads an i18next JSON v4 file into messages, using # as the context separator.
+func FromI18next(inputPath string) ([]message.Message, error) {
+	return FromI18nextWithOptions(inputPath, I18nextOptions{})
+}
+
+// FromI18nextWithOptions reads an i18next JSON v4 file into messages.
+//
+// Nested keys are joined with dots, and sibling keys with plural suffixes (items_one, items_other) become
+// one plural message. A key with a context suffix, such as friend_male next to friend, becomes friend#male
+// using the ContextSeparator. Nesting such as $t(common.ok) is resolved into the text of the nested message,
+// and interpolations such as {{name}} become template fields such as {{.name}}; formats are dropped.
+// Nesting an unknown key, or nesting that loops back on itself, is reported as an error.
+func FromI18nextWithOptions(inputPath string, opts I18nextOptions) ([]message.Message, error) {
+	separator := opts.ContextSeparator
+	if separator == "" {
+		separator = "#"
+	}
+
+	var data map[string]any
+	if err := DecodeJSONFile(inputPath, &data); err != nil {
+		return nil, err
+	}
+	var messages []message.Message
+	FlattenDataToMessagesWithOptions(data, &messages, "", FlattenOptions{DisableMessageDetection: true})
+	if len(messages) == 0 {
+		return nil, nil
+	}
+
+	byID := map[string]*message.Message{}
+	for i := range messages {
+		byID[messages[i].ID] = &messages[i]
+	}
+
+	// resolve replaces the nesting in text, following nested keys through the given chain of keys.
+	var resolve func(text string, chain []string) (string, error)
+	resolve = func(text string, chain []string) (string, error) {
+		var resolveErr error
+		text = rI18nextNesting.ReplaceAllStringFunc(text, func(nesting string) string {
+			key := strings.Trim(rI18nextNesting.FindStringSubmatch(nesting)[1], `"'`)
+			nested, ok := byID[key]
+			if _, after, found := strings.Cut(key, ":"); !ok && found {
+				// Nesting from a namespace, such as $t(common:ok), is looked up without the namespace.
+				key = after
+				nested, ok = byID[key]
+			}
+			if !ok {
+				resolveErr = fmt.Errorf("%q nests unknown key %q", chain[0], key)
+				return nesting
+			}
+			for _, id := range chain {
+				if id == key {
+					resolveErr = fmt.Errorf("%q nests itself through %s", chain[0], strings.Join(append(chain, key), " -> "))
+					return nesting
+				}
+			}
+			resolved, err := resolve(nested.Other, append(chain, key))
+			if err != nil {
+				resolveErr = err
+			}
+			return resolved
+		})
+		return text, resolveErr
+	}
+
+	resolved := make([]message.Message, len(messages))
+	for i, msg := range messages {
+		for _, category := range message.PluralCategories {
+			form, err := resolve(msg.PluralForm(category), []string{msg.ID})
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", inputPath, err)
+			}
+			msg.SetPluralForm(category, rI18nextInterpolation.ReplaceAllString(form, "{{.$1}}"))
+		}
+		resolved[i] = msg
+	}
+
+	for i, msg := range resolved {
+		index := strings.LastIndex(msg.ID, "_")
+		if index <= strings.LastIndex(msg.ID, ".")+1 {
+			continue
+		}
+		if _, ok := byID[msg.ID[:index]]; ok {
+			resolved[i].ID = msg.ID[:index] + separator + msg.ID[index+1:]
+		}
+	}
+	sort.Slice(resolved, func(i, j int) bool {
+		return resolved[i].ID < resolved[j].ID
+	})
+	return resolved, nil
+}
+
+// ToI18next converts a slice of message.Message objects into an i18next JSON v4 file, using # as the context separator.
+func ToI18next(messages []message.Message) (string, error) {
+	return ToI18nextWithOptions(messages, I18nextOptions{})
+}
+
+// ToI18nextWithOptions converts a slice of message.Message objects into an i18next JSON v4 file.
+//
+// Dotted IDs become nested keys, plural messages become keys with plural suffixes (items_one, items_other),
+// and IDs containing the ContextSeparator, such as friend#male, become context suffixes (friend_male).
+// Template fields such as {{.name}} become interpolations such as {{name}}. Descriptions are not written,
+// as i18next has no place for them. An ID that is both a message and the parent of other IDs is an error.
+func ToI18nextWithOptions(messages []message.Message, opts I18nextOptions) (string, error) {
+	separator := opts.ContextSeparator
+	if separator == "" {
+		separator = "#"
+	}
+
+	root := map[string]any{}
+	for _, msg := range messages {
+		key := msg.ID
+		if index := strings.LastIndex(key, separator); index > 0 {
+			key = key[:index] + "_" + key[index+len(separator):]
+		}
+		path := strings.Split(key, ".")
+		if !msg.IsPlural() {
+			if err := nestValue(root, path, rTemplatePath.ReplaceAllString(msg.Other, "{{$1}}")); err != nil {
+				return "", fmt.Errorf("message %q: %w", msg.ID, err)
+			}
+			continue
+		}
+		last := len(path) - 1
+		for _, category := range message.PluralCategories {
+			if form := msg.PluralForm(category); form != "" {
+				formPath := append(append([]string{}, path[:last]...), path[last]+"_"+category)
+				if err := nestValue(root, formPath, rTemplatePath.ReplaceAllString(form, "{{$1}}")); err != nil {
+					return "", fmt.Errorf("message %q: %w", msg.ID, err)
+				}
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(root); err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(buf.String(), "\n"), nil
+}
+
+// nestValue stores value in root under the nested keys of path, creating the maps in between.
+// It fails when the path runs through a value or ends on an existing key.
+func nestValue(root map[string]any, path []string, value any) error {
+	current := root
+	for i, key := range path[:len(path)-1] {
+		existing, ok := current[key]
+		if !ok {
+			next := map[string]any{}
+			current[key] = next
+			current = next
+			continue
+		}
+		next, ok := existing.(map[string]any)
+		if !ok {
+			return fmt.Errorf("key %q is both a value and a parent of other keys", strings.Join(path[:i+1], "."))
+		}
+		current = next
+	}
+	last := path[len(path)-1]
+	if existing, ok := current[last]; ok {
+		if _, isMap := existing.(map[string]any); isMap {
+			return fmt.Errorf("key %q is both a value and a parent of other keys", strings.Join(path, "."))
+		}
+		return fmt.Errorf("key %q is defined more than once", strings.Join(path, "."))
+	}
+	current[last] = value
+	return nil
+}
@@ -0,0 +1,90 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/s-nix/mk2i18n/message"
+	"github.com/stretchr/testify/assert"
+)
+
+const i18nextContent = `{
+  "common": {
+    "ok": "OK",
+    "app": "My App"
+  },
+  "welcome": "Welcome to $t(common.app), {{name}}!",
+  "price": "Costs {{amount, currency(USD)}}",
+  "raw": "{{- html}}",
+  "items_one": "{{count}} item",
+  "items_other": "{{count}} items",
+  "friend": "A friend",
+  "friend_male": "A boyfriend",
+  "friend_female_one": "{{count}} girlfriend",
+  "friend_female_other": "{{count}} girlfriends",
+  "submit_button": "Submit"
+}`
+
+func TestFromI18next(t *testing.T) {
+	messages, err := FromI18next(writeTempFile(t, "translation_*.json", i18nextContent))
+	assert.NoError(t, err)
+
+	expectedMessages := []message.Message{
+		{ID: "common.app", Other: "My App"},
+		{ID: "common.ok", Other: "OK"},
+		{ID: "friend", Other: "A friend"},
+		{ID: "friend#female", One: "{{.count}} girlfriend", Other: "{{.count}} girlfriends"},
+		{ID: "friend#male", Other: "A boyfriend"},
+		{ID: "items", One: "{{.count}} item", Other: "{{.count}} items"},
+		{ID: "price", Other: "Costs {{.amount}}"},
+		{ID: "raw", Other: "{{.html}}"},
+		{ID: "submit_button", Other: "Submit"},
+		{ID: "welcome", Other: "Welcome to My App, {{.name}}!"},
+	}
+	assert.Equal(t, expectedMessages, messages)
+}
+
+func TestFromI18next_Nesting(t *testing.T) {
+	_, err := FromI18next(writeTempFile(t, "translation_*.json", `{"a": "$t(missing)"}`))
+	assert.ErrorContains(t, err, `"a" nests unknown key "missing"`)
+
+	_, err = FromI18next(writeTempFile(t, "translation_*.json", `{"a": "$t(b)", "b": "$t(a)"}`))
+	assert.ErrorContains(t, err, "nests itself through")
+
+	messages, err := FromI18next(writeTempFile(t, "translation_*.json", `{"ok": "OK", "confirm": "Press $t(common:ok)"}`))
+	assert.NoError(t, err)
+	assert.Equal(t, "Press OK", messages[0].Other)
+}
+
+func TestToI18next(t *testing.T) {
+	messages := []message.Message{
+		{ID: "common.ok", Description: "Button label", Other: "OK"},
+		{ID: "friend", Other: "A friend"},
+		{ID: "friend/female", One: "{{.count}} girlfriend", Other: "{{.count}} girlfriends"},
+		{ID: "welcome", Other: "Hi {{.user.name}} & co"},
+	}
+	output, err := ToI18nextWithOptions(messages, I18nextOptions{ContextSeparator: "/"})
+	assert.NoError(t, err)
+
+	expected := `{
+  "common": {
+    "ok": "OK"
+  },
+  "friend": "A friend",
+  "friend_female_one": "{{count}} girlfriend",
+  "friend_female_other": "{{count}} girlfriends",
+  "welcome": "Hi {{user.name}} & co"
+}`
+	assert.Equal(t, expected, output)
+
+	roundTrip, err := FromI18nextWithOptions(writeTempFile(t, "translation_*.json", output), I18nextOptions{ContextSeparator: "/"})
+	assert.NoError(t, err)
+	messages[0].Description = ""
+	assert.Equal(t, messages, roundTrip)
+
+	output, err = ToI18next([]message.Message{{ID: "price", Other: `{{printf "%.2f" .Arg1}} EUR`}})
+	assert.NoError(t, err)
+	assert.Equal(t, "{\n  \"price\": \"{{Arg1}} EUR\"\n}", output, "printf fields become plain interpolations")
+
+	_, err = ToI18next([]message.Message{{ID: "a", Other: "A"}, {ID: "a.b", Other: "B"}})
+	assert.ErrorContains(t, err, `key "a" is both a value and a parent of other keys`)
+}
@@ -9,6 +9,24 @@ import (
 	"github.com/s-nix/mk2i18n/message"
 )
 
+// JSONFormat is the flavor of a JSON file.
+type JSONFormat string
+
+const (
+	// JSONFormatAuto detects Chrome extension message files, see DetectJSONFormat, and uses the generic flavor otherwise,
+	// as i18next files cannot be told apart from other nested JSON. Output is written in the generic flavor.
+	JSONFormatAuto JSONFormat = ""
+
+	// JSONFormatGeneric flattens any JSON document into messages, see FromJSON, and writes go-i18n message files, see ToJSON.
+	JSONFormatGeneric JSONFormat = "generic"
+
+	// JSONFormatI18next reads and writes i18next JSON v4 files, see FromI18next and ToI18next.
+	JSONFormatI18next JSONFormat = "i18next"
+
+	// JSONFormatChrome reads and writes Chrome extension messages.json files, see FromChromeMessages and ToChromeMessages.
+	JSONFormatChrome JSONFormat = "chrome"
+)
+
 // ToJSON converts a slice of message.Message objects into a pretty-printed JSON string.
 // Each message.Message is marshaled to JSON and combined into a single JSON object.
 func ToJSON(messages []message.Message) (string, error) {
@@ -30,6 +48,20 @@ func ToJSON(messages []message.Message) (string, error) {
 	return prettyJson.String(), nil
 }
 
+// ToNestedJSON converts a slice of message.Message objects into plain nested JSON, such as a frontend
+// reads, rather than go-i18n message objects. See UnflattenMessages for how the messages are nested.
+func ToNestedJSON(messages []message.Message) (string, error) {
+	data, err := UnflattenMessages(messages)
+	if err != nil {
+		return "", err
+	}
+	output, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(output) + "\n", nil
+}
+
 func DecodeJSONFile(path string, v any) error {
 	fp, err := os.Open(path)
 	if err != nil {
@@ -39,14 +71,20 @@ func DecodeJSONFile(path string, v any) error {
 	return json.NewDecoder(fp).Decode(v)
 }
 
+// FromJSON reads a JSON file and flattens it into messages.
 func FromJSON(inputPath string) ([]message.Message, error) {
+	return FromJSONWithOptions(inputPath, FlattenOptions{})
+}
+
+// FromJSONWithOptions reads a JSON file and flattens it into messages using the given options.
+func FromJSONWithOptions(inputPath string, opts FlattenOptions) ([]message.Message, error) {
 	var messages []message.Message
 	var data map[string]any
 	err := DecodeJSONFile(inputPath, &data)
 	if err != nil {
 		return nil, err
 	}
-	FlattenDataToMessages(data, &messages, "")
+	FlattenDataToMessagesWithOptions(data, &messages, "", opts)
 	if len(messages) == 0 {
 		return nil, nil
 	}
@@ -69,13 +69,8 @@ func TestFromJson(t *testing.T) {
 
 	expectedMessages := []message.Message{
 		{
-			ID:          "farewell.description",
-			Description: "",
-			Other:       "A farewell message",
-		},
-		{
-			ID:          "farewell.other",
-			Description: "",
+			ID:          "farewell",
+			Description: "A farewell message",
 			Other:       "1",
 		},
 		{
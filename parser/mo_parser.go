@@ -0,0 +1,108 @@
+package parser
+
+import (
+	"encoding/binary"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/s-nix/mk2i18n/message"
+)
+
+const (
+	// moMagic is the magic number of GNU MO files, read in the byte order the file was written in.
+	moMagic = 0x950412de
+
+	// moHeaderSize is the size of the fixed MO header up to and including the hash table offset.
+	moHeaderSize = 28
+)
+
+// FromMO reads a compiled gettext MO file into messages.
+func FromMO(inputPath string) ([]message.Message, error) {
+	return FromMOWithOptions(inputPath, POOptions{})
+}
+
+// FromMOWithOptions reads a compiled gettext MO file into messages using the given options.
+//
+// Both little and big endian files are supported; the hash table is ignored.
+// Messages are built like FromPOWithOptions builds them: the ID is the context and the msgid joined
+// with a dot ("menu.Open") when a context is present, or the context alone when IDAsContext is set,
+// and plural translations are mapped to the plural forms of the Language and Plural-Forms headers.
+// MO files carry no comments, so descriptions are left empty.
+func FromMOWithOptions(inputPath string, opts POOptions) ([]message.Message, error) {
+	content, err := os.ReadFile(inputPath)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := readMOEntries(content)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", inputPath, err)
+	}
+	return poEntriesToMessages(inputPath, entries, opts)
+}
+
+// readMOEntries decodes the string tables of an MO file into catalog entries.
+func readMOEntries(content []byte) ([]*poEntry, error) {
+	if len(content) < moHeaderSize {
+		return nil, fmt.Errorf("file too short for an MO header")
+	}
+
+	var order binary.ByteOrder
+	switch {
+	case binary.LittleEndian.Uint32(content) == moMagic:
+		order = binary.LittleEndian
+	case binary.BigEndian.Uint32(content) == moMagic:
+		order = binary.BigEndian
+	default:
+		return nil, fmt.Errorf("invalid MO magic number %#x", binary.LittleEndian.Uint32(content))
+	}
+
+	if revision := order.Uint32(content[4:]) >> 16; revision > 1 {
+		return nil, fmt.Errorf("unsupported MO major revision %d", revision)
+	}
+	count := int(order.Uint32(content[8:]))
+	originalsOffset := int(order.Uint32(content[12:]))
+	translationsOffset := int(order.Uint32(content[16:]))
+
+	// readString returns the string described by the table entry at the given offset.
+	readString := func(tableOffset int, index int) (string, error) {
+		position := tableOffset + index*8
+		if position < 0 || position+8 > len(content) {
+			return "", fmt.Errorf("string table entry %d out of range", index)
+		}
+		length := int(order.Uint32(content[position:]))
+		offset := int(order.Uint32(content[position+4:]))
+		if offset < 0 || length < 0 || offset+length > len(content) {
+			return "", fmt.Errorf("string %d out of range", index)
+		}
+		return string(content[offset : offset+length]), nil
+	}
+
+	var entries []*poEntry
+	for i := 0; i < count; i++ {
+		original, err := readString(originalsOffset, i)
+		if err != nil {
+			return nil, err
+		}
+		translation, err := readString(translationsOffset, i)
+		if err != nil {
+			return nil, err
+		}
+
+		entry := &poEntry{line: i + 1, msgstr: map[int]string{}}
+		if context, msgid, found := strings.Cut(original, "\x04"); found {
+			entry.msgctxt = &context
+			original = msgid
+		}
+		entry.msgid, entry.msgidPlural, _ = strings.Cut(original, "\x00")
+		if entry.msgidPlural == "" {
+			entry.msgstr[0] = translation
+		} else {
+			for index, form := range strings.Split(translation, "\x00") {
+				entry.msgstr[index] = form
+			}
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
@@ -0,0 +1,67 @@
+package parser
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/s-nix/mk2i18n/message"
+	"github.com/stretchr/testify/assert"
+)
+
+// buildMO encodes original and translated string pairs as an MO file without hash table.
+func buildMO(order binary.ByteOrder, pairs [][2]string) []byte {
+	count := len(pairs)
+	originalsOffset := moHeaderSize
+	translationsOffset := originalsOffset + count*8
+	stringsOffset := translationsOffset + count*8
+
+	var header, originals, translations, data bytes.Buffer
+	for _, value := range []uint32{moMagic, 0, uint32(count), uint32(originalsOffset), uint32(translationsOffset), 0, uint32(stringsOffset)} {
+		_ = binary.Write(&header, order, value)
+	}
+	for _, pair := range pairs {
+		for index, table := range []*bytes.Buffer{&originals, &translations} {
+			_ = binary.Write(table, order, uint32(len(pair[index])))
+			_ = binary.Write(table, order, uint32(stringsOffset+data.Len()))
+			data.WriteString(pair[index])
+			data.WriteByte(0)
+		}
+	}
+	return append(append(append(header.Bytes(), originals.Bytes()...), translations.Bytes()...), data.Bytes()...)
+}
+
+func TestFromMO(t *testing.T) {
+	pairs := [][2]string{
+		{"", "Language: pl\nPlural-Forms: nplurals=3; plural=(n==1 ? 0 : n%10>=2 && n%10<=4 && (n%100<10 || n%100>=20) ? 1 : 2);\n"},
+		{"greeting", "Cześć"},
+		{"menu\x04Open", "Otwórz"},
+		{"file\x00files", "{{.Count}} plik\x00{{.Count}} pliki\x00{{.Count}} plików"},
+	}
+	expectedMessages := []message.Message{
+		{ID: "file", One: "{{.Count}} plik", Few: "{{.Count}} pliki", Many: "{{.Count}} plików", Other: "{{.Count}} plików"},
+		{ID: "greeting", Other: "Cześć"},
+		{ID: "menu.Open", Other: "Otwórz"},
+	}
+
+	for _, order := range []binary.ByteOrder{binary.LittleEndian, binary.BigEndian} {
+		messages, err := FromMO(writeTempFile(t, "test_*.mo", string(buildMO(order, pairs))))
+		assert.NoError(t, err, order.String())
+		assert.Equal(t, expectedMessages, messages, order.String())
+	}
+}
+
+func TestFromMO_Errors(t *testing.T) {
+	_, err := FromMO(writeTempFile(t, "test_*.mo", "short"))
+	assert.ErrorContains(t, err, "too short")
+
+	_, err = FromMO(writeTempFile(t, "test_*.mo", string(make([]byte, moHeaderSize))))
+	assert.ErrorContains(t, err, "invalid MO magic number")
+
+	content := buildMO(binary.LittleEndian, [][2]string{{"greeting", "Hello"}})
+	_, err = FromMO(writeTempFile(t, "test_*.mo", string(content[:len(content)-8])))
+	assert.ErrorContains(t, err, "out of range")
+
+	_, err = FromMO("does-not-exist.mo")
+	assert.Error(t, err)
+}
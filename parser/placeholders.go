@@ -0,0 +1,182 @@
+package parser
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/s-nix/mk2i18n/message"
+)
+
+// rPrintfVerb matches printf style placeholders such as %s, %d, %1$s, %.2f and %%,
+// including the length modifiers and the %@ object placeholder of Apple format strings, such as %ld and %1$@.
+// The space flag is left out so that text like "100% sure" is not taken for a placeholder.
+var rPrintfVerb = regexp.MustCompile(`%(?:(\d+)\$)?[-#+0,(]*\d*(?:\.\d+)?(?:hh|h|ll|l|q|L|z|t|j)?([a-zA-Z%@])`)
+
+// rPrintfSpec splits a placeholder matched by rPrintfVerb into its flags and its width and precision.
+var rPrintfSpec = regexp.MustCompile(`^%(?:\d+\$)?([-#+0,(]*)(\d*(?:\.\d+)?)`)
+
+// rTemplateArg matches the template fields written by printfToTemplate, such as {{.Arg1}} and {{printf "%.2f" .Arg1}}.
+// The first group is the argument number.
+var rTemplateArg = regexp.MustCompile(`\{\{\s*(?:printf\s+"%[-#+0 ]*\d*(?:\.\d+)?[a-zA-Z]"\s+)?\.Arg(\d+)\s*\}\}`)
+
+// rTemplatePrintf matches the printf call of a template field matched by rTemplateArg; the group is its spec without the %.
+var rTemplatePrintf = regexp.MustCompile(`^\{\{\s*printf\s+"%([^"]*)"`)
+
+// rTemplateCount matches the {{.Count}} field of plural messages.
+var rTemplateCount = regexp.MustCompile(`\{\{\s*\.Count\s*\}\}`)
+
+// printfGoVerbs maps the printf conversions that printfToTemplate keeps to their Go equivalent.
+// The other conversions, such as %s and %@, become plain template fields.
+var printfGoVerbs = map[string]string{
+	"d": "d", "i": "d", "u": "d", "x": "x", "X": "X", "o": "o",
+	"f": "f", "F": "f", "e": "e", "E": "E", "g": "g", "G": "G", "c": "c",
+}
+
+// printfToTemplate converts printf style placeholders into go-i18n template fields.
+// Positional placeholders like %2$s become {{.Arg2}}, the others are numbered in order of appearance,
+// so "%s has %d items" becomes "{{.Arg1}} has {{printf "%d" .Arg2}} items". Numeric and character
+// conversions keep their flags, width and precision in a printf call, without the C length modifiers,
+// while strings and objects become plain fields. A literal %% becomes %.
+// A placeholder without position inside a word, such as the %d of "20%discount", is literal text,
+// and values without placeholders are returned unchanged, %% included.
+func printfToTemplate(value string) string {
+	var sb strings.Builder
+	last := 0
+	next := 0
+	converted := false
+	for _, match := range rPrintfVerb.FindAllStringSubmatchIndex(value, -1) {
+		sb.WriteString(value[last:match[0]])
+		last = match[1]
+		verb := value[match[4]:match[5]]
+		position := 0
+		switch {
+		case verb == "%":
+			sb.WriteString("%")
+			continue
+		case match[2] >= 0:
+			position, _ = strconv.Atoi(value[match[2]:match[3]])
+		case inWord(value, match[0], match[1]):
+			sb.WriteString(value[match[0]:match[1]])
+			continue
+		default:
+			next++
+			position = next
+		}
+		converted = true
+		goVerb, ok := printfGoVerbs[verb]
+		if !ok {
+			sb.WriteString(fmt.Sprintf("{{.Arg%d}}", position))
+			continue
+		}
+		spec := rPrintfSpec.FindStringSubmatch(value[match[0]:match[1]])
+		flags := strings.NewReplacer(",", "", "(", "").Replace(spec[1])
+		sb.WriteString(fmt.Sprintf("{{printf \"%%%s%s%s\" .Arg%d}}", flags, spec[2], goVerb, position))
+	}
+	if !converted {
+		return value
+	}
+	sb.WriteString(value[last:])
+	return sb.String()
+}
+
+// inWord reports whether value[start:end] is directly preceded and followed by a letter or digit.
+func inWord(value string, start, end int) bool {
+	before, _ := utf8.DecodeLastRuneInString(value[:start])
+	after, _ := utf8.DecodeRuneInString(value[end:])
+	return isWordRune(before) && isWordRune(after)
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// templateToPrintf reverses printfToTemplate.
+// Template fields like {{.Arg2}} become positional placeholders with the given verb, such as %2$s for "s",
+// fields with a printf call keep its spec, such as %2$.2f, and a literal % becomes %%.
+// Values without template fields are returned unchanged.
+func templateToPrintf(value string, verb string) string {
+	return formatTemplateArgs(value, verb, "")
+}
+
+// templateToApplePrintf converts template fields into the placeholders of Apple format strings.
+// Plain fields become objects such as %2$@, and integers formatted with printf get the length of Swift's Int,
+// as Xcode writes them: {{printf "%d" .Arg1}} becomes %1$lld. The {{.Count}} field of plural messages becomes
+// the integer argument with the given number.
+func templateToApplePrintf(value string, count int) string {
+	value = rTemplateCount.ReplaceAllString(value, fmt.Sprintf(`{{printf "%%d" .Arg%d}}`, count))
+	return formatTemplateArgs(value, "@", "ll")
+}
+
+// appleCountArg returns the argument number of the {{.Count}} field in Apple format strings written from
+// the forms of a message, which is the one after the highest {{.ArgN}} field of its forms.
+func appleCountArg(msg message.Message) int {
+	count := 1
+	for _, category := range message.PluralCategories {
+		for _, match := range rTemplateArg.FindAllStringSubmatch(msg.PluralForm(category), -1) {
+			if arg, _ := strconv.Atoi(match[1]); arg >= count {
+				count = arg + 1
+			}
+		}
+	}
+	return count
+}
+
+// hasTemplateCount reports whether any form of a message has the {{.Count}} field.
+func hasTemplateCount(msg message.Message) bool {
+	for _, category := range message.PluralCategories {
+		if rTemplateCount.MatchString(msg.PluralForm(category)) {
+			return true
+		}
+	}
+	return false
+}
+
+// formatTemplateArgs writes the template fields of value as positional printf placeholders:
+// plain fields with the object conversion, and fields with a printf call with its spec,
+// with the integer length modifier added to integer conversions.
+func formatTemplateArgs(value, object, integerLength string) string {
+	matches := rTemplateArg.FindAllStringSubmatchIndex(value, -1)
+	if len(matches) == 0 {
+		return value
+	}
+	var sb strings.Builder
+	last := 0
+	for _, match := range matches {
+		sb.WriteString(strings.ReplaceAll(value[last:match[0]], "%", "%%"))
+		spec := object
+		if printf := rTemplatePrintf.FindStringSubmatch(value[match[0]:match[1]]); printf != nil {
+			spec = printfSpec(printf[1], integerLength)
+		}
+		sb.WriteString("%" + value[match[2]:match[3]] + "$" + spec)
+		last = match[1]
+	}
+	sb.WriteString(strings.ReplaceAll(value[last:], "%", "%%"))
+	return sb.String()
+}
+
+// templateArgConversion returns the conversion, with the integer length modifier, that formatTemplateArgs writes
+// for the first printf field of the given argument in value, such as lld for {{printf "%d" .Arg1}} and "ll".
+// It reports false when the argument has no printf field.
+func templateArgConversion(value string, arg int, integerLength string) (string, bool) {
+	for _, match := range rTemplateArg.FindAllStringSubmatch(value, -1) {
+		printf := rTemplatePrintf.FindStringSubmatch(match[0])
+		if printf != nil && match[1] == strconv.Itoa(arg) {
+			spec := printfSpec(printf[1], integerLength)
+			return strings.TrimLeft(spec, "-#+ .0123456789"), true
+		}
+	}
+	return "", false
+}
+
+// printfSpec adds the integer length modifier to the spec of a printf template field when it converts an integer.
+func printfSpec(spec string, integerLength string) string {
+	conversion := spec[len(spec)-1:]
+	if strings.Contains("dxXo", conversion) {
+		return spec[:len(spec)-1] + integerLength + conversion
+	}
+	return spec
+}
@@ -0,0 +1,57 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrintfToTemplate(t *testing.T) {
+	tests := map[string]string{
+		"Hello %s":                  "Hello {{.Arg1}}",
+		"%s has %d items":           `{{.Arg1}} has {{printf "%d" .Arg2}} items`,
+		"%2$s before %1$s":          "{{.Arg2}} before {{.Arg1}}",
+		"%.2f%% done":               `{{printf "%.2f" .Arg1}}% done`,
+		"100% sure":                 "100% sure",
+		"%ld files in %@":           `{{printf "%d" .Arg1}} files in {{.Arg2}}`,
+		"%2$@ before %1$lld":        `{{.Arg2}} before {{printf "%d" .Arg1}}`,
+		"%u and %#X and %F":         `{{printf "%d" .Arg1}} and {{printf "%#X" .Arg2}} and {{printf "%f" .Arg3}}`,
+		"No placeholders":           "No placeholders",
+		"20%discount for %s":        "20%discount for {{.Arg1}}",
+		"50%off":                    "50%off",
+		"Save 100%% now":            "Save 100%% now",
+		"%1$-10s and %05d and %,d ": `{{.Arg1}} and {{printf "%05d" .Arg1}} and {{printf "%d" .Arg2}} `,
+	}
+	for input, expected := range tests {
+		assert.Equal(t, expected, printfToTemplate(input), input)
+	}
+}
+
+func TestTemplateToPrintf(t *testing.T) {
+	tests := map[string]string{
+		"Hello {{.Arg1}}":              "Hello %1$s",
+		"{{.Arg2}} before {{ .Arg1 }}": "%2$s before %1$s",
+		"{{.Arg1}}% done":              "%1$s%% done",
+		"{{.Name}} is not an argument": "{{.Name}} is not an argument",
+		"No placeholders":              "No placeholders",
+		"50% off":                      "50% off",
+		`{{printf "%.2f" .Arg1}}`:      "%1$.2f",
+		`{{printf "%05d" .Arg2}} left`: "%2$05d left",
+	}
+	for input, expected := range tests {
+		assert.Equal(t, expected, templateToPrintf(input, "s"), input)
+	}
+}
+
+func TestTemplateToApplePrintf(t *testing.T) {
+	assert.Equal(t, "%2$@ before %1$@", templateToApplePrintf("{{.Arg2}} before {{.Arg1}}", 1))
+	assert.Equal(t, "%1$lld files in %2$@, %3$.1f%%", templateToApplePrintf(`{{printf "%d" .Arg1}} files in {{.Arg2}}, {{printf "%.1f" .Arg3}}%`, 1))
+	assert.Equal(t, "%2$lld files in %1$@", templateToApplePrintf("{{.Count}} files in {{.Arg1}}", 2))
+	assert.Equal(t, "50% off", templateToApplePrintf("50% off", 1))
+
+	conversion, ok := templateArgConversion(`{{.Arg2}} of {{printf "%5x" .Arg1}}`, 1, "ll")
+	assert.True(t, ok)
+	assert.Equal(t, "llx", conversion)
+	_, ok = templateArgConversion("{{.Arg1}} items", 1, "ll")
+	assert.False(t, ok)
+}
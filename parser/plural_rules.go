@@ -0,0 +1,108 @@
+package parser
+
+import (
+	"strings"
+
+	"github.com/s-nix/mk2i18n/message"
+)
+
+// pluralRule describes how a language numbers its plural forms in gettext catalogs.
+type pluralRule struct {
+	// categories holds the CLDR plural category of each msgstr[n] index.
+	categories []string
+
+	// formula is the C expression used in the Plural-Forms header.
+	formula string
+}
+
+var (
+	ruleOther           = pluralRule{[]string{"other"}, "0"}
+	ruleOneOther        = pluralRule{[]string{"one", "other"}, "(n != 1)"}
+	ruleOneIncludesZero = pluralRule{[]string{"one", "other"}, "(n > 1)"}
+	ruleEastSlavic      = pluralRule{[]string{"one", "few", "many"}, "(n%10==1 && n%100!=11 ? 0 : n%10>=2 && n%10<=4 && (n%100<10 || n%100>=20) ? 1 : 2)"}
+	ruleWestSlavic      = pluralRule{[]string{"one", "few", "other"}, "(n==1 ? 0 : n>=2 && n<=4 ? 1 : 2)"}
+	ruleSouthSlavic     = pluralRule{[]string{"one", "few", "other"}, "(n%10==1 && n%100!=11 ? 0 : n%10>=2 && n%10<=4 && (n%100<10 || n%100>=20) ? 1 : 2)"}
+)
+
+// pluralRules maps languages to their gettext plural rule.
+// Languages that are not listed use the rule of their plural count, see defaultPluralRules.
+var pluralRules = map[string]pluralRule{
+	"ja":    ruleOther,
+	"ko":    ruleOther,
+	"zh":    ruleOther,
+	"th":    ruleOther,
+	"vi":    ruleOther,
+	"id":    ruleOther,
+	"ms":    ruleOther,
+	"lo":    ruleOther,
+	"km":    ruleOther,
+	"my":    ruleOther,
+	"fr":    ruleOneIncludesZero,
+	"pt_br": ruleOneIncludesZero,
+	"ru":    ruleEastSlavic,
+	"uk":    ruleEastSlavic,
+	"be":    ruleEastSlavic,
+	"pl":    {[]string{"one", "few", "many"}, "(n==1 ? 0 : n%10>=2 && n%10<=4 && (n%100<10 || n%100>=20) ? 1 : 2)"},
+	"cs":    ruleWestSlavic,
+	"sk":    ruleWestSlavic,
+	"hr":    ruleSouthSlavic,
+	"sr":    ruleSouthSlavic,
+	"bs":    ruleSouthSlavic,
+	"lt":    {[]string{"one", "few", "other"}, "(n%10==1 && n%100!=11 ? 0 : n%10>=2 && (n%100<10 || n%100>=20) ? 1 : 2)"},
+	"lv":    {[]string{"one", "other", "zero"}, "(n%10==1 && n%100!=11 ? 0 : n != 0 ? 1 : 2)"},
+	"ro":    {[]string{"one", "few", "other"}, "(n==1 ? 0 : (n==0 || (n%100 > 0 && n%100 < 20)) ? 1 : 2)"},
+	"sl":    {[]string{"one", "two", "few", "other"}, "(n%100==1 ? 0 : n%100==2 ? 1 : n%100==3 || n%100==4 ? 2 : 3)"},
+	"ga":    {[]string{"one", "two", "few", "many", "other"}, "(n==1 ? 0 : n==2 ? 1 : n<7 ? 2 : n<11 ? 3 : 4)"},
+	"ar":    {[]string{"zero", "one", "two", "few", "many", "other"}, "(n==0 ? 0 : n==1 ? 1 : n==2 ? 2 : n%100>=3 && n%100<=10 ? 3 : n%100>=11 ? 4 : 5)"},
+}
+
+// defaultPluralRules holds the rule used for each plural count when the language is unknown.
+var defaultPluralRules = map[int]pluralRule{
+	1: ruleOther,
+	2: ruleOneOther,
+	3: ruleEastSlavic,
+	4: pluralRules["sl"],
+	5: pluralRules["ga"],
+	6: pluralRules["ar"],
+}
+
+// normalizeLocale lower-cases a locale and uses underscores as separator, so that en-US becomes en_us.
+func normalizeLocale(locale string) string {
+	return strings.ReplaceAll(strings.ToLower(strings.TrimSpace(locale)), "-", "_")
+}
+
+// pluralRuleForLocale returns the gettext plural rule of a locale such as de, pt-BR or sr_RS.
+// The full locale is looked up first, then its language. Unknown locales use the English rule.
+func pluralRuleForLocale(locale string) pluralRule {
+	locale = normalizeLocale(locale)
+	if rule, ok := pluralRules[locale]; ok {
+		return rule
+	}
+	language, _, _ := strings.Cut(locale, "_")
+	if rule, ok := pluralRules[language]; ok {
+		return rule
+	}
+	return ruleOneOther
+}
+
+// pluralCategoriesFor returns the CLDR plural categories of the msgstr[n] indexes of a catalog
+// with the given locale and plural count. When the locale rule does not have that many forms,
+// the default rule for the count is used.
+func pluralCategoriesFor(locale string, count int) []string {
+	if rule := pluralRuleForLocale(locale); len(rule.categories) == count {
+		return rule.categories
+	}
+	if rule, ok := defaultPluralRules[count]; ok {
+		return rule.categories
+	}
+	return nil
+}
+
+// fillOtherForm sets the Other form of a plural message read with the given categories to the form of the last
+// category when it is empty, as for languages such as ru and pl whose rules have no other category.
+// go-i18n uses Other for counts that match no category, such as fractions.
+func fillOtherForm(msg *message.Message, categories []string) {
+	if msg.Other == "" {
+		msg.Other = msg.PluralForm(categories[len(categories)-1])
+	}
+}
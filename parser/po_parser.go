@@ -0,0 +1,417 @@
+package parser
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/s-nix/mk2i18n/message"
+)
+
+// POOptions configures how gettext PO files are read and written.
+type POOptions struct {
+	// IncludeFuzzy uses the translations of entries flagged as fuzzy when reading.
+	// By default, like msgfmt, fuzzy translations are treated as untranslated.
+	IncludeFuzzy bool
+
+	// Locale is the language written to the Language header, such as de or pt_BR.
+	// It selects the number of plural forms and the Plural-Forms expression. Defaults to English rules.
+	Locale string
+
+	// IDAsContext writes the message ID as msgctxt and the message text as msgid, and reads the msgctxt
+	// of an entry alone as its message ID. By default the message ID is written as msgid, and read as
+	// the msgctxt and msgid of an entry joined with a dot, such as menu.Open.
+	IDAsContext bool
+}
+
+// poEntry holds the raw fields of a single PO entry.
+type poEntry struct {
+	// line is the line of the entry in a PO file, or its index in an MO file.
+	line        int
+	comments    []string
+	flags       []string
+	obsolete    bool
+	msgctxt     *string
+	msgid       string
+	msgidPlural string
+	msgstr      map[int]string
+}
+
+// isHeader reports whether the entry is the catalog header, the entry with an empty msgid and no context.
+func (e *poEntry) isHeader() bool {
+	return e.msgid == "" && e.msgctxt == nil
+}
+
+// isFuzzy reports whether the entry carries the fuzzy flag.
+func (e *poEntry) isFuzzy() bool {
+	for _, flag := range e.flags {
+		if flag == "fuzzy" {
+			return true
+		}
+	}
+	return false
+}
+
+var (
+	rPOKeyword      = regexp.MustCompile(`^(msgctxt|msgid_plural|msgid|msgstr(?:\[(\d+)\])?)\s+(".*")$`)
+	rPONPlurals     = regexp.MustCompile(`nplurals\s*=\s*(\d+)`)
+	rPOHeaderLocale = regexp.MustCompile(`(?m)^Language:\s*(\S*)`)
+	rPOHeaderPlural = regexp.MustCompile(`(?m)^Plural-Forms:\s*(.*)$`)
+)
+
+// ToPO converts a slice of message.Message objects into a gettext PO catalog using English plural rules.
+func ToPO(messages []message.Message) (string, error) {
+	return ToPOWithOptions(messages, POOptions{})
+}
+
+// ToPOWithOptions converts a slice of message.Message objects into a gettext PO catalog using the given options.
+// Descriptions are written as extracted comments (#.). Plural messages are written with msgid_plural
+// and one msgstr[n] per plural form of the locale; missing forms fall back to Other.
+func ToPOWithOptions(messages []message.Message, opts POOptions) (string, error) {
+	rule := pluralRuleForLocale(opts.Locale)
+
+	var sb strings.Builder
+	sb.WriteString("msgid \"\"\n")
+	header := "MIME-Version: 1.0\n" +
+		"Content-Type: text/plain; charset=UTF-8\n" +
+		"Content-Transfer-Encoding: 8bit\n"
+	if opts.Locale != "" {
+		header = "Language: " + opts.Locale + "\n" + header
+	}
+	header += fmt.Sprintf("Plural-Forms: nplurals=%d; plural=%s;\n", len(rule.categories), rule.formula)
+	writePOString(&sb, "msgstr", header)
+
+	for _, msg := range messages {
+		sb.WriteString("\n")
+		if msg.Description != "" {
+			for _, line := range strings.Split(msg.Description, "\n") {
+				sb.WriteString(strings.TrimRight("#. "+line, " ") + "\n")
+			}
+		}
+
+		msgid, msgidPlural := msg.ID, msg.ID
+		if opts.IDAsContext {
+			writePOString(&sb, "msgctxt", msg.ID)
+			msgid, msgidPlural = msg.Other, msg.Other
+			if msg.One != "" {
+				msgid = msg.One
+			}
+		}
+		writePOString(&sb, "msgid", msgid)
+
+		if !msg.IsPlural() {
+			writePOString(&sb, "msgstr", msg.Other)
+			continue
+		}
+		writePOString(&sb, "msgid_plural", msgidPlural)
+		for index, category := range rule.categories {
+			form := msg.PluralForm(category)
+			if form == "" {
+				form = msg.Other
+			}
+			writePOString(&sb, fmt.Sprintf("msgstr[%d]", index), form)
+		}
+	}
+	return sb.String(), nil
+}
+
+// writePOString writes a keyword line with its quoted value.
+// Values spanning several lines are split after each newline, the way gettext tools format them.
+func writePOString(sb *strings.Builder, keyword string, value string) {
+	lines := strings.SplitAfter(value, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	if len(lines) <= 1 {
+		sb.WriteString(keyword + " " + quotePOString(value) + "\n")
+		return
+	}
+	sb.WriteString(keyword + " \"\"\n")
+	for _, line := range lines {
+		sb.WriteString(quotePOString(line) + "\n")
+	}
+}
+
+// quotePOString encodes a string as a double-quoted PO string with C escape sequences.
+func quotePOString(value string) string {
+	replacer := strings.NewReplacer(
+		"\\", "\\\\",
+		"\"", "\\\"",
+		"\n", "\\n",
+		"\t", "\\t",
+		"\r", "\\r",
+	)
+	return "\"" + replacer.Replace(value) + "\""
+}
+
+// FromPO reads a gettext PO or POT file into messages.
+func FromPO(inputPath string) ([]message.Message, error) {
+	return FromPOWithOptions(inputPath, POOptions{})
+}
+
+// FromPOWithOptions reads a gettext PO or POT file into messages using the given options.
+//
+// The message ID is the msgid of an entry, prefixed with its msgctxt and a dot when it has one, or the msgctxt
+// alone with IDAsContext. Other holds msgstr, or for plural entries msgstr[n] are mapped to the plural forms
+// of the catalog language taken from the Language and Plural-Forms headers, and Other falls back to the last
+// form when the language has no other category. Untranslated entries fall back to msgid and msgid_plural.
+// Extracted comments (#.) become the Description. Obsolete entries (#~) are skipped.
+func FromPOWithOptions(inputPath string, opts POOptions) ([]message.Message, error) {
+	entries, err := readPOEntries(inputPath)
+	if err != nil {
+		return nil, err
+	}
+	return poEntriesToMessages(inputPath, entries, opts)
+}
+
+// poEntriesToMessages converts the entries of a PO or MO catalog into messages sorted by ID.
+func poEntriesToMessages(inputPath string, entries []*poEntry, opts POOptions) ([]message.Message, error) {
+	locale := ""
+	nplurals := 2
+	for _, entry := range entries {
+		if !entry.isHeader() {
+			continue
+		}
+		header := entry.msgstr[0]
+		if match := rPOHeaderLocale.FindStringSubmatch(header); match != nil {
+			locale = match[1]
+		}
+		if match := rPOHeaderPlural.FindStringSubmatch(header); match != nil {
+			if n := rPONPlurals.FindStringSubmatch(match[1]); n != nil {
+				nplurals, _ = strconv.Atoi(n[1])
+			}
+		}
+	}
+	categories := pluralCategoriesFor(locale, nplurals)
+
+	var messages []message.Message
+	seen := map[string]int{}
+	for _, entry := range entries {
+		if entry.isHeader() || entry.obsolete {
+			continue
+		}
+		msg := message.Message{
+			ID:          entry.msgid,
+			Description: strings.Join(entry.comments, "\n"),
+		}
+		if entry.msgctxt != nil {
+			if opts.IDAsContext {
+				msg.ID = *entry.msgctxt
+			} else {
+				msg.ID = *entry.msgctxt + "." + entry.msgid
+			}
+		}
+		if line, exists := seen[msg.ID]; exists {
+			return nil, fmt.Errorf("%s:%d: duplicate message ID %q, first defined at line %d", inputPath, entry.line, msg.ID, line)
+		}
+		seen[msg.ID] = entry.line
+
+		translated := !entry.isFuzzy() || opts.IncludeFuzzy
+		if entry.msgidPlural == "" {
+			msg.Other = entry.msgstr[0]
+			if !translated || msg.Other == "" {
+				msg.Other = entry.msgid
+			}
+		} else {
+			if categories == nil {
+				return nil, fmt.Errorf("%s:%d: unsupported plural count %d", inputPath, entry.line, nplurals)
+			}
+			hasTranslation := false
+			for _, str := range entry.msgstr {
+				if str != "" {
+					hasTranslation = true
+				}
+			}
+			if translated && hasTranslation {
+				for index, str := range entry.msgstr {
+					if index < len(categories) {
+						msg.SetPluralForm(categories[index], str)
+					}
+				}
+				fillOtherForm(&msg, categories)
+			} else {
+				msg.One = entry.msgid
+				msg.Other = entry.msgidPlural
+			}
+		}
+		messages = append(messages, msg)
+	}
+
+	if len(messages) == 0 {
+		return nil, nil
+	}
+	sort.Slice(messages, func(i, j int) bool {
+		return messages[i].ID < messages[j].ID
+	})
+	return messages, nil
+}
+
+// readPOEntries splits a PO file into its entries.
+func readPOEntries(inputPath string) ([]*poEntry, error) {
+	fp, err := os.Open(inputPath)
+	if err != nil {
+		return nil, err
+	}
+	defer fp.Close()
+
+	var entries []*poEntry
+	entry := &poEntry{msgstr: map[int]string{}}
+	hasContent := false
+	// appendTo adds the strings of continuation lines to the keyword read last.
+	var appendTo func(string)
+
+	flush := func() {
+		if hasContent {
+			entries = append(entries, entry)
+		}
+		entry = &poEntry{msgstr: map[int]string{}}
+		hasContent = false
+		appendTo = nil
+	}
+
+	scanner := bufio.NewScanner(fp)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if lineNumber == 1 {
+			line = strings.TrimPrefix(line, "\ufeff")
+		}
+
+		if line == "" {
+			flush()
+			continue
+		}
+
+		if strings.HasPrefix(line, "#~") {
+			// Obsolete entries keep their keywords behind the #~ marker.
+			if len(entry.msgstr) > 0 && !entry.obsolete {
+				flush()
+			}
+			entry.obsolete = true
+			hasContent = true
+			continue
+		}
+
+		if strings.HasPrefix(line, "#") {
+			if len(entry.msgstr) > 0 {
+				flush()
+			}
+			switch {
+			case strings.HasPrefix(line, "#."):
+				entry.comments = append(entry.comments, strings.TrimSpace(line[2:]))
+			case strings.HasPrefix(line, "#,"):
+				for _, flag := range strings.Split(line[2:], ",") {
+					entry.flags = append(entry.flags, strings.TrimSpace(flag))
+				}
+			}
+			continue
+		}
+
+		if strings.HasPrefix(line, `"`) {
+			if appendTo == nil {
+				return nil, fmt.Errorf("%s:%d: unexpected string continuation", inputPath, lineNumber)
+			}
+			str, err := unquotePOString(line)
+			if err != nil {
+				return nil, fmt.Errorf("%s:%d: %w", inputPath, lineNumber, err)
+			}
+			appendTo(str)
+			continue
+		}
+
+		match := rPOKeyword.FindStringSubmatch(line)
+		if match == nil {
+			return nil, fmt.Errorf("%s:%d: unexpected line %q", inputPath, lineNumber, line)
+		}
+		keyword := match[1]
+		if entry.obsolete || (keyword == "msgctxt" || keyword == "msgid") && len(entry.msgstr) > 0 {
+			flush()
+		}
+		str, err := unquotePOString(match[3])
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", inputPath, lineNumber, err)
+		}
+		if !hasContent {
+			entry.line = lineNumber
+		}
+		hasContent = true
+
+		current := entry
+		switch keyword {
+		case "msgctxt":
+			current.msgctxt = &str
+			appendTo = func(s string) { *current.msgctxt += s }
+		case "msgid":
+			current.msgid = str
+			appendTo = func(s string) { current.msgid += s }
+		case "msgid_plural":
+			current.msgidPlural = str
+			appendTo = func(s string) { current.msgidPlural += s }
+		default:
+			index := 0
+			if match[2] != "" {
+				index, _ = strconv.Atoi(match[2])
+			}
+			current.msgstr[index] = str
+			appendTo = func(s string) { current.msgstr[index] += s }
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	flush()
+	return entries, nil
+}
+
+// unquotePOString decodes a double-quoted PO string with its C escape sequences.
+func unquotePOString(quoted string) (string, error) {
+	if len(quoted) < 2 || quoted[0] != '"' || quoted[len(quoted)-1] != '"' {
+		return "", fmt.Errorf("invalid string %s", quoted)
+	}
+	content := quoted[1 : len(quoted)-1]
+	var sb strings.Builder
+	for i := 0; i < len(content); i++ {
+		c := content[i]
+		if c != '\\' {
+			sb.WriteByte(c)
+			continue
+		}
+		i++
+		if i >= len(content) {
+			return "", fmt.Errorf("invalid escape at end of string %s", quoted)
+		}
+		switch content[i] {
+		case 'n':
+			sb.WriteByte('\n')
+		case 't':
+			sb.WriteByte('\t')
+		case 'r':
+			sb.WriteByte('\r')
+		case 'a':
+			sb.WriteByte('\a')
+		case 'b':
+			sb.WriteByte('\b')
+		case 'f':
+			sb.WriteByte('\f')
+		case 'v':
+			sb.WriteByte('\v')
+		case '0', '1', '2', '3', '4', '5', '6', '7':
+			end := i
+			for end < len(content) && end < i+3 && content[end] >= '0' && content[end] <= '7' {
+				end++
+			}
+			value, _ := strconv.ParseUint(content[i:end], 8, 8)
+			sb.WriteByte(byte(value))
+			i = end - 1
+		default:
+			sb.WriteByte(content[i])
+		}
+	}
+	return sb.String(), nil
+}
@@ -0,0 +1,226 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/s-nix/mk2i18n/message"
+	"github.com/stretchr/testify/assert"
+)
+
+const poContent = `# Translator comment that is ignored
+msgid ""
+msgstr ""
+"Project-Id-Version: test\n"
+"Language: ru\n"
+"Plural-Forms: nplurals=3; plural=(n%10==1 && n%100!=11 ? 0 : n%10>=2 && n%10<=4 && (n%100<10 || n%100>=20) ? 1 : 2);\n"
+
+#. Shown on the start page
+#. Keep it short
+#: main.go:12
+msgid "greeting"
+msgstr "Привет"
+
+msgctxt "menu"
+msgid "Open"
+msgstr "Открыть"
+
+msgctxt "menu"
+msgid "Close"
+msgstr "Закрыть"
+
+#, fuzzy, c-format
+msgid "farewell"
+msgstr "Пока"
+
+msgid "multiline"
+msgstr ""
+"First line\n"
+"Second \"quoted\" line"
+
+#. Number of files
+msgid "{{.Count}} file"
+msgid_plural "{{.Count}} files"
+msgstr[0] "{{.Count}} файл"
+msgstr[1] "{{.Count}} файла"
+msgstr[2] "{{.Count}} файлов"
+
+msgid "untranslated"
+msgid_plural "untranslated items"
+msgstr[0] ""
+msgstr[1] ""
+msgstr[2] ""
+
+#~ msgid "obsolete"
+#~ msgstr "Устарело"
+`
+
+func TestFromPO(t *testing.T) {
+	path := writeTempFile(t, "test_*.po", poContent)
+
+	messages, err := FromPO(path)
+	assert.NoError(t, err)
+
+	expectedMessages := []message.Message{
+		{ID: "farewell", Other: "farewell"},
+		{ID: "greeting", Description: "Shown on the start page\nKeep it short", Other: "Привет"},
+		{ID: "menu.Close", Other: "Закрыть"},
+		{ID: "menu.Open", Other: "Открыть"},
+		{ID: "multiline", Other: "First line\nSecond \"quoted\" line"},
+		{ID: "untranslated", One: "untranslated", Other: "untranslated items"},
+		{
+			ID:          "{{.Count}} file",
+			Description: "Number of files",
+			One:         "{{.Count}} файл",
+			Few:         "{{.Count}} файла",
+			Many:        "{{.Count}} файлов",
+			Other:       "{{.Count}} файлов",
+		},
+	}
+	assert.Equal(t, expectedMessages, messages)
+}
+
+func TestFromPOWithOptions_IDAsContext(t *testing.T) {
+	path := writeTempFile(t, "test_*.po", `msgctxt "greeting"
+msgid "Hello"
+msgstr "Hallo"
+
+msgid "farewell"
+msgstr "Tschüss"
+`)
+
+	messages, err := FromPOWithOptions(path, POOptions{IDAsContext: true})
+	assert.NoError(t, err)
+	assert.Equal(t, []message.Message{{ID: "farewell", Other: "Tschüss"}, {ID: "greeting", Other: "Hallo"}}, messages)
+
+	_, err = FromPOWithOptions(writeTempFile(t, "test_*.po", poContent), POOptions{IDAsContext: true})
+	assert.ErrorContains(t, err, `duplicate message ID "menu"`)
+}
+
+func TestFromPOWithOptions_IncludeFuzzy(t *testing.T) {
+	path := writeTempFile(t, "test_*.po", poContent)
+
+	messages, err := FromPOWithOptions(path, POOptions{IncludeFuzzy: true})
+	assert.NoError(t, err)
+	assert.Equal(t, message.Message{ID: "farewell", Other: "Пока"}, messages[0])
+}
+
+func TestFromPO_Template(t *testing.T) {
+	potContent := `msgid ""
+msgstr ""
+"Content-Type: text/plain; charset=UTF-8\n"
+
+msgid "Hello"
+msgstr ""
+
+msgid "One apple"
+msgid_plural "%d apples"
+msgstr[0] ""
+msgstr[1] ""
+`
+	path := writeTempFile(t, "test_*.po", potContent)
+
+	messages, err := FromPO(path)
+	assert.NoError(t, err)
+
+	expectedMessages := []message.Message{
+		{ID: "Hello", Other: "Hello"},
+		{ID: "One apple", One: "One apple", Other: "%d apples"},
+	}
+	assert.Equal(t, expectedMessages, messages)
+}
+
+func TestFromPO_Errors(t *testing.T) {
+	path := writeTempFile(t, "test_*.po", "msgid \"a\"\nmsgstr \"b\"\n\nmsgid \"a\"\nmsgstr \"c\"\n")
+	_, err := FromPO(path)
+	assert.ErrorContains(t, err, `duplicate message ID "a"`)
+
+	path = writeTempFile(t, "test_*.po", "msgid \"a\"\nunknown \"b\"\n")
+	_, err = FromPO(path)
+	assert.ErrorContains(t, err, ":2: unexpected line")
+
+	_, err = FromPO("does-not-exist.po")
+	assert.Error(t, err)
+}
+
+func TestToPO(t *testing.T) {
+	messages := []message.Message{
+		{ID: "greeting", Description: "A greeting message\nShown on start", Other: "Hallo"},
+		{ID: "items", One: "{{.Count}} Artikel", Other: "{{.Count}} Artikel gesamt"},
+		{ID: "quote", Other: "Er sagte \"Hallo\"\nund ging"},
+	}
+
+	expectedPO := `msgid ""
+msgstr ""
+"Language: de\n"
+"MIME-Version: 1.0\n"
+"Content-Type: text/plain; charset=UTF-8\n"
+"Content-Transfer-Encoding: 8bit\n"
+"Plural-Forms: nplurals=2; plural=(n != 1);\n"
+
+#. A greeting message
+#. Shown on start
+msgid "greeting"
+msgstr "Hallo"
+
+msgid "items"
+msgid_plural "items"
+msgstr[0] "{{.Count}} Artikel"
+msgstr[1] "{{.Count}} Artikel gesamt"
+
+msgid "quote"
+msgstr ""
+"Er sagte \"Hallo\"\n"
+"und ging"
+`
+
+	output, err := ToPOWithOptions(messages, POOptions{Locale: "de"})
+	assert.NoError(t, err)
+	assert.Equal(t, expectedPO, output)
+}
+
+func TestToPO_IDAsContext(t *testing.T) {
+	messages := []message.Message{
+		{ID: "greeting", Other: "Привет"},
+		{ID: "files", One: "{{.Count}} файл", Few: "{{.Count}} файла", Other: "{{.Count}} файлов"},
+	}
+
+	expectedPO := `msgid ""
+msgstr ""
+"Language: ru\n"
+"MIME-Version: 1.0\n"
+"Content-Type: text/plain; charset=UTF-8\n"
+"Content-Transfer-Encoding: 8bit\n"
+"Plural-Forms: nplurals=3; plural=(n%10==1 && n%100!=11 ? 0 : n%10>=2 && n%10<=4 && (n%100<10 || n%100>=20) ? 1 : 2);\n"
+
+msgctxt "greeting"
+msgid "Привет"
+msgstr "Привет"
+
+msgctxt "files"
+msgid "{{.Count}} файл"
+msgid_plural "{{.Count}} файлов"
+msgstr[0] "{{.Count}} файл"
+msgstr[1] "{{.Count}} файла"
+msgstr[2] "{{.Count}} файлов"
+`
+
+	output, err := ToPOWithOptions(messages, POOptions{Locale: "ru", IDAsContext: true})
+	assert.NoError(t, err)
+	assert.Equal(t, expectedPO, output)
+}
+
+func TestToPO_RoundTrip(t *testing.T) {
+	messages := []message.Message{
+		{ID: "cart", Description: "Cart summary", One: "one item", Other: "{{.Count}} items"},
+		{ID: "greeting", Other: "Hello\tWorld \\o/"},
+	}
+
+	for _, idAsContext := range []bool{false, true} {
+		output, err := ToPOWithOptions(messages, POOptions{IDAsContext: idAsContext})
+		assert.NoError(t, err)
+
+		parsed, err := FromPOWithOptions(writeTempFile(t, "test_*.po", output), POOptions{IDAsContext: idAsContext})
+		assert.NoError(t, err)
+		assert.Equal(t, messages, parsed)
+	}
+}
@@ -1,27 +1,414 @@
 package parser
 
 import (
+	"bytes"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+
 	"github.com/magiconair/properties"
 	"github.com/s-nix/mk2i18n/message"
 )
 
+// PropertiesComments selects which comment lines above a property become its description.
+type PropertiesComments string
+
+const (
+	// PropertiesCommentsBlock keeps the comment block directly above the property, up to the first blank line.
+	// It is the default.
+	PropertiesCommentsBlock PropertiesComments = "block"
+
+	// PropertiesCommentsNearest keeps only the comment line directly above the property.
+	PropertiesCommentsNearest PropertiesComments = "nearest"
+
+	// PropertiesCommentsAll keeps every comment line between the previous property and this one,
+	// including those separated from it by blank lines.
+	PropertiesCommentsAll PropertiesComments = "all"
+)
+
+// PropertiesEncoding is the character encoding of a Java .properties file.
+type PropertiesEncoding string
+
+const (
+	// PropertiesEncodingAuto reads UTF-8 files, with or without a byte order mark, as UTF-8 and other files as
+	// ISO-8859-1, and writes UTF-8.
+	PropertiesEncodingAuto PropertiesEncoding = ""
+
+	// PropertiesEncodingUTF8 reads and writes UTF-8, the default of Java 9 resource bundles.
+	PropertiesEncodingUTF8 PropertiesEncoding = "utf-8"
+
+	// PropertiesEncodingISO88591 reads ISO-8859-1, the encoding of classic Java resource bundles, and writes
+	// ASCII with every other character escaped as \uXXXX, which any Java version reads.
+	PropertiesEncodingISO88591 PropertiesEncoding = "iso-8859-1"
+)
+
+// PropertiesOptions configures how Java .properties files are read and written.
+type PropertiesOptions struct {
+	// Comments selects which comment lines above a property become its Description. Defaults to PropertiesCommentsBlock.
+	Comments PropertiesComments
+
+	// IgnoreHeader skips the comment block at the top of the file, such as a license, when a blank line
+	// separates it from the rest of the file.
+	IgnoreHeader bool
+
+	// Encoding is the character encoding of the file. Defaults to PropertiesEncodingAuto.
+	// \uXXXX escapes are decoded in every encoding.
+	Encoding PropertiesEncoding
+
+	// DisablePluralDetection keeps keys with a plural category suffix, such as items.one and items.other,
+	// as separate messages instead of reading them as one plural message.
+	DisablePluralDetection bool
+}
+
+// FromProperties reads a Java .properties file into messages, in file order, with the comment block above each property as its Description.
 func FromProperties(inputPath string) ([]message.Message, error) {
-	props, err := properties.LoadFile(inputPath, properties.UTF8)
+	return FromPropertiesWithOptions(inputPath, PropertiesOptions{})
+}
+
+// FromPropertiesWithOptions reads a Java .properties file into messages, in file order.
+// The # or ! comment lines above a property become its Description, as selected by opts.
+// Unless plural detection is disabled, keys sharing a base name with plural category suffixes, such as
+// items.one and items.other as ToPropertiesWithOptions writes them, become one plural message at the place
+// of the first of them, with the first description among them. The other category and at least one more
+// category are required, so a lone key such as greeting.other stays a message of its own, and a base name that
+// is a key of its own is not grouped.
+func FromPropertiesWithOptions(inputPath string, opts PropertiesOptions) ([]message.Message, error) {
+	switch opts.Comments {
+	case "", PropertiesCommentsBlock, PropertiesCommentsNearest, PropertiesCommentsAll:
+	default:
+		return nil, fmt.Errorf("unsupported properties comment mode: %s", opts.Comments)
+	}
+
+	content, err := os.ReadFile(inputPath)
+	if err != nil {
+		return nil, err
+	}
+	text, err := decodeProperties(content, opts.Encoding)
 	if err != nil {
 		return nil, err
 	}
+	// ${...} is text in resource bundles, so the library must not expand it as a reference to another key.
+	text = joinSurrogateEscapes(text)
+	props, err := (&properties.Loader{Encoding: properties.UTF8, DisableExpansion: true}).LoadBytes([]byte(text))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", inputPath, err)
+	}
+	descriptions := propertiesDescriptions(text, opts)
 
+	groups := map[string]map[string]string{}
+	if !opts.DisablePluralDetection {
+		groups = propertiesPluralGroups(props)
+	}
 	var messages []message.Message
+	plurals := map[string]int{}
 	for _, key := range props.Keys() {
 		value, ok := props.Get(key)
 		if !ok {
 			continue
 		}
-		msg := message.Message{
-			ID:    key,
-			Other: value,
+		base, category, found := cutPluralSuffix(key)
+		if !found || groups[base] == nil {
+			messages = append(messages, message.Message{
+				ID:          key,
+				Description: descriptions[key],
+				Other:       value,
+			})
+			continue
+		}
+		index, ok := plurals[base]
+		if !ok {
+			index = len(messages)
+			plurals[base] = index
+			messages = append(messages, message.Message{ID: base})
+		}
+		messages[index].SetPluralForm(category, value)
+		if messages[index].Description == "" {
+			messages[index].Description = descriptions[key]
 		}
-		messages = append(messages, msg)
 	}
 	return messages, nil
 }
+
+// propertiesPluralGroups finds the keys with a plural category suffix (items.one, items.other) of props.
+// It returns the keys grouped by their base name and plural category. Only groups that contain an other key and
+// another category, and whose base name is not a key itself, are returned.
+func propertiesPluralGroups(props *properties.Properties) map[string]map[string]string {
+	groups := map[string]map[string]string{}
+	for _, key := range props.Keys() {
+		if base, category, found := cutPluralSuffix(key); found {
+			if groups[base] == nil {
+				groups[base] = map[string]string{}
+			}
+			groups[base][category] = key
+		}
+	}
+	for base, keys := range groups {
+		if _, isKey := props.Get(base); isKey || keys["other"] == "" || len(keys) < 2 {
+			delete(groups, base)
+		}
+	}
+	return groups
+}
+
+// cutPluralSuffix splits a key such as items.one into its base name and plural category.
+// It reports false when the part after the last dot is not a plural category.
+func cutPluralSuffix(key string) (base, category string, found bool) {
+	index := strings.LastIndex(key, ".")
+	if index <= 0 || !slices.Contains(message.PluralCategories, key[index+1:]) {
+		return "", "", false
+	}
+	return key[:index], key[index+1:], true
+}
+
+// ToProperties converts a slice of message.Message objects into a UTF-8 Java .properties file with one key=value line per message.
+func ToProperties(messages []message.Message) (string, error) {
+	return ToPropertiesWithOptions(messages, PropertiesOptions{})
+}
+
+// ToPropertiesWithOptions converts a slice of message.Message objects into a Java .properties file with one key=value
+// line per message, sorted by key. The Description of a message is written as # comment lines above it, and
+// the plural forms of a plural message are written as keys with the plural category as suffix, such as
+// items.one and items.other. Keys and values are escaped so that Java reads them back unchanged, and with the
+// ISO-8859-1 encoding every character outside ASCII is written as a \uXXXX escape.
+func ToPropertiesWithOptions(messages []message.Message, opts PropertiesOptions) (string, error) {
+	escapeUnicode := false
+	switch strings.ToLower(string(opts.Encoding)) {
+	case string(PropertiesEncodingAuto), string(PropertiesEncodingUTF8):
+	case string(PropertiesEncodingISO88591):
+		escapeUnicode = true
+	default:
+		return "", fmt.Errorf("unsupported properties encoding: %s", opts.Encoding)
+	}
+
+	sorted := slices.Clone(messages)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].ID < sorted[j].ID
+	})
+
+	var sb strings.Builder
+	for _, msg := range sorted {
+		if msg.Description != "" {
+			description := strings.ReplaceAll(strings.ReplaceAll(msg.Description, "\r\n", "\n"), "\r", "\n")
+			for _, line := range strings.Split(description, "\n") {
+				sb.WriteString(strings.TrimRight("# "+escapePropertiesComment(line, escapeUnicode), " "))
+				sb.WriteString("\n")
+			}
+		}
+		if !msg.IsPlural() {
+			sb.WriteString(escapeProperties(msg.ID, true, escapeUnicode))
+			sb.WriteString("=")
+			sb.WriteString(escapeProperties(msg.Other, false, escapeUnicode))
+			sb.WriteString("\n")
+			continue
+		}
+		for _, category := range message.PluralCategories {
+			form := msg.PluralForm(category)
+			if form == "" && category != "other" {
+				continue
+			}
+			sb.WriteString(escapeProperties(msg.ID+"."+category, true, escapeUnicode))
+			sb.WriteString("=")
+			sb.WriteString(escapeProperties(form, false, escapeUnicode))
+			sb.WriteString("\n")
+		}
+	}
+	return sb.String(), nil
+}
+
+// decodeProperties decodes the content of a .properties file in the given encoding, detecting it when
+// the encoding is PropertiesEncodingAuto.
+func decodeProperties(content []byte, encoding PropertiesEncoding) (string, error) {
+	switch strings.ToLower(string(encoding)) {
+	case string(PropertiesEncodingAuto):
+		if !bytes.HasPrefix(content, []byte{0xEF, 0xBB, 0xBF}) && !utf8.Valid(content) {
+			return decodeLatin1(content), nil
+		}
+	case string(PropertiesEncodingUTF8):
+	case string(PropertiesEncodingISO88591):
+		return decodeLatin1(content), nil
+	default:
+		return "", fmt.Errorf("unsupported properties encoding: %s", encoding)
+	}
+	return strings.TrimPrefix(string(content), "\ufeff"), nil
+}
+
+// rSurrogateEscape matches a UTF-16 surrogate pair written as two \uXXXX escapes, with the backslashes before it.
+var rSurrogateEscape = regexp.MustCompile(`(\\+)u([dD][89abAB][0-9a-fA-F]{2})\\u([dD][c-fC-F][0-9a-fA-F]{2})`)
+
+// joinSurrogateEscapes replaces escaped UTF-16 surrogate pairs, such as \uD83D\uDE00, with the character they
+// encode, as Java reads them. The properties library decodes each half on its own, which loses the character.
+func joinSurrogateEscapes(text string) string {
+	return rSurrogateEscape.ReplaceAllStringFunc(text, func(escape string) string {
+		match := rSurrogateEscape.FindStringSubmatch(escape)
+		if len(match[1])%2 == 0 {
+			// The first backslash is escaped itself, so this is not an escape.
+			return escape
+		}
+		high, _ := strconv.ParseUint(match[2], 16, 16)
+		low, _ := strconv.ParseUint(match[3], 16, 16)
+		return match[1][1:] + string(utf16.DecodeRune(rune(high), rune(low)))
+	})
+}
+
+// decodeLatin1 decodes ISO-8859-1 content, whose bytes are the first 256 Unicode code points.
+func decodeLatin1(content []byte) string {
+	runes := make([]rune, len(content))
+	for i, b := range content {
+		runes[i] = rune(b)
+	}
+	return string(runes)
+}
+
+// escapeProperties escapes a key or value for a .properties file the way java.util.Properties stores them:
+// backslashes, control characters, the separators = and :, and the comment characters # and ! are escaped,
+// and so are all spaces of a key but only the leading spaces of a value. With escapeUnicode, characters
+// outside printable ASCII are written as \uXXXX escapes, using surrogate pairs beyond the BMP.
+func escapeProperties(text string, isKey bool, escapeUnicode bool) string {
+	var sb strings.Builder
+	leading := true
+	for _, r := range text {
+		switch {
+		case r == ' ':
+			if isKey || leading {
+				sb.WriteString(`\ `)
+			} else {
+				sb.WriteByte(' ')
+			}
+			continue
+		case r == '\\':
+			sb.WriteString(`\\`)
+		case r == '\t':
+			sb.WriteString(`\t`)
+		case r == '\n':
+			sb.WriteString(`\n`)
+		case r == '\r':
+			sb.WriteString(`\r`)
+		case r == '\f':
+			sb.WriteString(`\f`)
+		case r == '=' || r == ':' || r == '#' || r == '!':
+			sb.WriteByte('\\')
+			sb.WriteRune(r)
+		case r < 0x20 || r == 0x7f || escapeUnicode && r > 0x7e:
+			if r1, r2 := utf16.EncodeRune(r); r1 != utf8.RuneError {
+				fmt.Fprintf(&sb, `\u%04X\u%04X`, r1, r2)
+			} else {
+				fmt.Fprintf(&sb, `\u%04X`, r)
+			}
+		default:
+			sb.WriteRune(r)
+		}
+		leading = false
+	}
+	return sb.String()
+}
+
+// escapePropertiesComment escapes a line of a .properties comment. Comments need no escaping, except that
+// with escapeUnicode, characters outside printable ASCII are written as \uXXXX escapes as by escapeProperties.
+func escapePropertiesComment(text string, escapeUnicode bool) string {
+	if !escapeUnicode {
+		return text
+	}
+	var sb strings.Builder
+	for _, r := range text {
+		if r < 0x20 && r != '\t' || r > 0x7e {
+			sb.WriteString(escapeProperties(string(r), false, true))
+		} else {
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
+
+// propertiesDescriptions collects the comment lines above each property of a .properties file, keyed by property key.
+func propertiesDescriptions(content string, opts PropertiesOptions) map[string]string {
+	content = strings.ReplaceAll(strings.ReplaceAll(content, "\r\n", "\n"), "\r", "\n")
+	lines := strings.Split(content, "\n")
+
+	start := 0
+	if opts.IgnoreHeader {
+		// The header is the comment lines at the top of the file when a blank line follows them.
+		end := 0
+		for end < len(lines) && isPropertiesComment(lines[end]) {
+			end++
+		}
+		if end > 0 && end < len(lines) && strings.TrimLeft(lines[end], " \t\f") == "" {
+			start = end
+		}
+	}
+
+	descriptions := map[string]string{}
+	var comments []string
+	for i := start; i < len(lines); i++ {
+		line := strings.TrimLeft(lines[i], " \t\f")
+		switch {
+		case line == "":
+			if opts.Comments != PropertiesCommentsAll {
+				comments = nil
+			}
+		case isPropertiesComment(line):
+			text := strings.TrimPrefix(line[1:], " ")
+			if opts.Comments == PropertiesCommentsNearest {
+				comments = nil
+			}
+			comments = append(comments, text)
+		default:
+			// A line ending in an odd number of backslashes continues on the next line.
+			for strings.HasSuffix(line, `\`) && (len(line)-len(strings.TrimRight(line, `\`)))%2 == 1 && i+1 < len(lines) {
+				i++
+				line = line[:len(line)-1] + strings.TrimLeft(lines[i], " \t\f")
+			}
+			descriptions[propertiesKey(line)] = strings.Join(comments, "\n")
+			comments = nil
+		}
+	}
+	return descriptions
+}
+
+// isPropertiesComment reports whether a line of a .properties file is a comment.
+func isPropertiesComment(line string) bool {
+	line = strings.TrimLeft(line, " \t\f")
+	return strings.HasPrefix(line, "#") || strings.HasPrefix(line, "!")
+}
+
+// propertiesKey returns the unescaped key of a logical .properties line, which ends at the first
+// unescaped =, : or whitespace.
+func propertiesKey(line string) string {
+	var sb strings.Builder
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case c == '=' || c == ':' || c == ' ' || c == '\t' || c == '\f':
+			return sb.String()
+		case c == '\\' && i+1 < len(line):
+			i++
+			switch line[i] {
+			case 't':
+				sb.WriteByte('\t')
+			case 'n':
+				sb.WriteByte('\n')
+			case 'r':
+				sb.WriteByte('\r')
+			case 'f':
+				sb.WriteByte('\f')
+			case 'u':
+				if code, err := strconv.ParseUint(line[i+1:min(i+5, len(line))], 16, 32); err == nil && i+5 <= len(line) {
+					sb.WriteRune(rune(code))
+					i += 4
+				} else {
+					sb.WriteByte('u')
+				}
+			default:
+				sb.WriteByte(line[i])
+			}
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	return sb.String()
+}
@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/magiconair/properties"
+	"github.com/s-nix/mk2i18n/message"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -47,3 +48,154 @@ farewell=Goodbye
 	err = os.Remove(tmpFilePath)
 	assert.NoError(t, err)
 }
+
+func TestFromPropertiesComments(t *testing.T) {
+	propsContent := `# Copyright Example Corp.
+# Licensed under the Apache License 2.0
+
+# Greetings
+
+# Shown on the start page
+! after login
+greeting = Hello
+farewell : Goodbye, \
+    see you
+# Escaped key
+wel\u0063ome\ back=Welcome back
+`
+	path := writeTempFile(t, "messages_*.properties", propsContent)
+
+	descriptions := func(opts PropertiesOptions) []string {
+		messages, err := FromPropertiesWithOptions(path, opts)
+		assert.NoError(t, err)
+		var result []string
+		for _, msg := range messages {
+			result = append(result, msg.ID+": "+msg.Description)
+		}
+		return result
+	}
+
+	assert.Equal(t, []string{
+		"greeting: Shown on the start page\nafter login",
+		"farewell: ",
+		"welcome back: Escaped key",
+	}, descriptions(PropertiesOptions{}))
+
+	assert.Equal(t, []string{
+		"greeting: after login",
+		"farewell: ",
+		"welcome back: Escaped key",
+	}, descriptions(PropertiesOptions{Comments: PropertiesCommentsNearest}))
+
+	assert.Equal(t, []string{
+		"greeting: Copyright Example Corp.\nLicensed under the Apache License 2.0\nGreetings\nShown on the start page\nafter login",
+		"farewell: ",
+		"welcome back: Escaped key",
+	}, descriptions(PropertiesOptions{Comments: PropertiesCommentsAll}))
+
+	assert.Equal(t, []string{
+		"greeting: Greetings\nShown on the start page\nafter login",
+		"farewell: ",
+		"welcome back: Escaped key",
+	}, descriptions(PropertiesOptions{Comments: PropertiesCommentsAll, IgnoreHeader: true}))
+
+	_, err := FromPropertiesWithOptions(path, PropertiesOptions{Comments: "first"})
+	assert.ErrorContains(t, err, "unsupported properties comment mode: first")
+}
+
+func TestFromPropertiesEncoding(t *testing.T) {
+	others := func(path string, encoding PropertiesEncoding) []string {
+		messages, err := FromPropertiesWithOptions(path, PropertiesOptions{Encoding: encoding})
+		assert.NoError(t, err)
+		var result []string
+		for _, msg := range messages {
+			result = append(result, msg.ID+"="+msg.Other)
+		}
+		return result
+	}
+
+	latin1 := writeTempFile(t, "messages_*.properties", "# Gr\xfc\xdfe\nstreet=Stra\xdfe\nescaped=Gr\\u00fc\\u00DFe \\uD83D\\uDE00\n")
+	assert.Equal(t, []string{"street=Straße", "escaped=Grüße 😀"}, others(latin1, PropertiesEncodingAuto))
+	assert.Equal(t, []string{"street=Straße", "escaped=Grüße 😀"}, others(latin1, PropertiesEncodingISO88591))
+
+	utf8File := writeTempFile(t, "messages_*.properties", "\xef\xbb\xbfstreet=Straße\n")
+	assert.Equal(t, []string{"street=Straße"}, others(utf8File, PropertiesEncodingAuto))
+	assert.Equal(t, []string{"street=StraÃ\u009fe"}, others(writeTempFile(t, "messages_*.properties", "street=Straße\n"), PropertiesEncodingISO88591))
+
+	escapedKey := writeTempFile(t, "messages_*.properties", "# Smiley key\nk\\uD83D\\uDE00=Hi ${name}, ${missing\n")
+	messages, err := FromProperties(escapedKey)
+	assert.NoError(t, err)
+	assert.Equal(t, []message.Message{{ID: "k😀", Description: "Smiley key", Other: "Hi ${name}, ${missing"}}, messages)
+
+	_, err = FromPropertiesWithOptions(utf8File, PropertiesOptions{Encoding: "utf-16"})
+	assert.ErrorContains(t, err, "unsupported properties encoding: utf-16")
+}
+
+func TestToProperties(t *testing.T) {
+	messages := []message.Message{
+		{ID: "street", Other: "Straße 😀"},
+		{ID: "key with spaces:and=signs", Other: "  leading spaces, a = sign\nand a new line"},
+		{ID: "path", Other: `C:\temp #1 !`},
+	}
+
+	output, err := ToProperties(messages)
+	assert.NoError(t, err)
+	assert.Equal(t, `key\ with\ spaces\:and\=signs=\ \ leading spaces, a \= sign\nand a new line
+path=C\:\\temp \#1 \!
+street=Straße 😀
+`, output)
+
+	output, err = ToPropertiesWithOptions(messages, PropertiesOptions{Encoding: PropertiesEncodingISO88591})
+	assert.NoError(t, err)
+	assert.Contains(t, output, `street=Stra\u00DFe \uD83D\uDE00`+"\n")
+
+	roundTrip, err := FromProperties(writeTempFile(t, "messages_*.properties", output))
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, messages, roundTrip)
+}
+
+func TestToPropertiesDescriptionsAndPlurals(t *testing.T) {
+	messages := []message.Message{
+		{ID: "items", Description: "Items in the cart\nShown below the total", One: "{{.Count}} item", Other: "{{.Count}} items"},
+		{ID: "greeting", Description: "Größer", Other: "Hello"},
+		{ID: "empty", Zero: "No items"},
+	}
+
+	output, err := ToProperties(messages)
+	assert.NoError(t, err)
+	path := writeTempFile(t, "messages_*.properties", output)
+	assert.Equal(t, `empty.zero=No items
+empty.other=
+# Größer
+greeting=Hello
+# Items in the cart
+# Shown below the total
+items.one={{.Count}} item
+items.other={{.Count}} items
+`, output)
+
+	output, err = ToPropertiesWithOptions(messages, PropertiesOptions{Encoding: PropertiesEncodingISO88591})
+	assert.NoError(t, err)
+	assert.Contains(t, output, "# Gr\\u00F6\\u00DFer\n")
+
+	descriptions := propertiesDescriptions(output, PropertiesOptions{})
+	assert.Equal(t, "Items in the cart\nShown below the total", descriptions["items.one"])
+	assert.Equal(t, "", descriptions["items.other"])
+
+	roundTrip, err := FromProperties(path)
+	assert.NoError(t, err)
+	assert.Equal(t, []message.Message{messages[2], messages[1], messages[0]}, roundTrip)
+
+	separate, err := FromPropertiesWithOptions(path, PropertiesOptions{DisablePluralDetection: true})
+	assert.NoError(t, err)
+	assert.Len(t, separate, 5)
+
+	lone, err := FromProperties(writeTempFile(t, "messages_*.properties", "items=Items\nitems.one=One\nitems.other=Many\nsaved.other=Saved\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, []message.Message{
+		{ID: "items", Other: "Items"},
+		{ID: "items.one", Other: "One"},
+		{ID: "items.other", Other: "Many"},
+		{ID: "saved.other", Other: "Saved"},
+	}, lone, "keys whose base name is a key, and lone other keys, are not grouped")
+}
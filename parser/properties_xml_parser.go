@@ -0,0 +1,103 @@
+package parser
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/s-nix/mk2i18n/message"
+)
+
+// propertiesDTD is the system identifier of the document type of Java XML properties files.
+const propertiesDTD = "http://java.sun.com/dtd/properties.dtd"
+
+// FromPropertiesXML reads a Java properties file stored with Properties.storeToXML into messages.
+//
+// Each entry element becomes a message with its key attribute as ID and its text as Other; as in Java, a
+// repeated key keeps its last value. An XML comment right before an entry becomes its Description.
+// The comment element is a note on the whole file rather than on its messages; see PropertiesXMLComment.
+func FromPropertiesXML(inputPath string) ([]message.Message, error) {
+	content, err := os.ReadFile(inputPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var messages []message.Message
+	index := map[string]int{}
+	decoder := xml.NewDecoder(bytes.NewReader(content))
+	depth := 0
+	comment := ""
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", inputPath, err)
+		}
+
+		switch tt := token.(type) {
+		case xml.Comment:
+			if depth == 1 {
+				comment = strings.TrimSpace(string(tt))
+			}
+		case xml.EndElement:
+			depth--
+		case xml.StartElement:
+			if depth == 0 {
+				if tt.Name.Local != "properties" {
+					return nil, fmt.Errorf("%s: root element is %s, not properties", inputPath, tt.Name.Local)
+				}
+				depth++
+				continue
+			}
+			var text string
+			if err := decoder.DecodeElement(&text, &tt); err != nil {
+				return nil, fmt.Errorf("%s: %w", inputPath, err)
+			}
+			description := comment
+			comment = ""
+			if tt.Name.Local == "entry" {
+				key := xmlAttr(tt, "key")
+				if key == "" {
+					return nil, fmt.Errorf("%s: entry has no key", inputPath)
+				}
+				msg := message.Message{ID: key, Description: description, Other: text}
+				if i, ok := index[key]; ok {
+					messages[i] = msg
+					continue
+				}
+				index[key] = len(messages)
+				messages = append(messages, msg)
+			}
+		}
+	}
+
+	if len(messages) == 0 {
+		return nil, nil
+	}
+	sort.Slice(messages, func(i, j int) bool {
+		return messages[i].ID < messages[j].ID
+	})
+	return messages, nil
+}
+
+// PropertiesXMLComment returns the comment element of a Java XML properties file, the note Properties.storeToXML
+// writes for the whole file, or an empty string when it has none.
+func PropertiesXMLComment(inputPath string) (string, error) {
+	content, err := os.ReadFile(inputPath)
+	if err != nil {
+		return "", err
+	}
+	var document struct {
+		Comment string `xml:"comment"`
+	}
+	if err := xml.Unmarshal(content, &document); err != nil {
+		return "", fmt.Errorf("%s: %w", inputPath, err)
+	}
+	return strings.TrimSpace(document.Comment), nil
+}
@@ -0,0 +1,55 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/s-nix/mk2i18n/message"
+	"github.com/stretchr/testify/assert"
+)
+
+const propertiesXMLContent = `<?xml version="1.0" encoding="UTF-8" standalone="no"?>
+<!DOCTYPE properties SYSTEM "http://java.sun.com/dtd/properties.dtd">
+<properties>
+<comment>Checkout messages</comment>
+<entry key="greeting">Hello, &lt;b&gt;world&lt;/b&gt;</entry>
+<!-- Shown after payment -->
+<entry key="thanks">Thank you!</entry>
+<entry key="multi.line">line one
+line two</entry>
+<entry key="greeting">Hello</entry>
+</properties>
+`
+
+func TestFromPropertiesXML(t *testing.T) {
+	messages, err := FromPropertiesXML(writeTempFile(t, "messages_*.xml", propertiesXMLContent))
+	assert.NoError(t, err)
+	assert.Equal(t, []message.Message{
+		{ID: "greeting", Other: "Hello"},
+		{ID: "multi.line", Other: "line one\nline two"},
+		{ID: "thanks", Description: "Shown after payment", Other: "Thank you!"},
+	}, messages)
+
+	comment, err := PropertiesXMLComment(writeTempFile(t, "messages_*.xml", propertiesXMLContent))
+	assert.NoError(t, err)
+	assert.Equal(t, "Checkout messages", comment)
+
+	_, err = FromPropertiesXML(writeTempFile(t, "messages_*.xml", `<properties><entry>No key</entry></properties>`))
+	assert.ErrorContains(t, err, "entry has no key")
+
+	_, err = FromPropertiesXML(writeTempFile(t, "messages_*.xml", `<resources/>`))
+	assert.ErrorContains(t, err, "root element is resources, not properties")
+}
+
+func TestDetectXMLFormatProperties(t *testing.T) {
+	format, err := DetectXMLFormat(writeTempFile(t, "messages_*.xml", propertiesXMLContent))
+	assert.NoError(t, err)
+	assert.Equal(t, XMLFormatProperties, format)
+
+	format, err = DetectXMLFormat(writeTempFile(t, "messages_*.properties.xml", `<properties><entry key="a">b</entry></properties>`))
+	assert.NoError(t, err)
+	assert.Equal(t, XMLFormatProperties, format)
+
+	format, err = DetectXMLFormat(writeTempFile(t, "messages_*.xml", `<properties><entry key="a">b</entry></properties>`))
+	assert.NoError(t, err)
+	assert.Equal(t, XMLFormatGeneric, format)
+}
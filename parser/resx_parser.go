@@ -0,0 +1,191 @@
+package parser
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/s-nix/mk2i18n/message"
+)
+
+// resxDocument is the root element of a .resx file.
+type resxDocument struct {
+	XMLName xml.Name   `xml:"root"`
+	Data    []resxData `xml:"data"`
+}
+
+// resxData is a data element of a .resx file.
+type resxData struct {
+	Name     string `xml:"name,attr"`
+	Type     string `xml:"type,attr"`
+	MimeType string `xml:"mimetype,attr"`
+	Value    string `xml:"value"`
+	Comment  string `xml:"comment"`
+}
+
+// resxHeader is the schema and resheader block Visual Studio writes at the start of every .resx file.
+const resxHeader = `<root>
+  <xsd:schema id="root" xmlns="" xmlns:xsd="http://www.w3.org/2001/XMLSchema" xmlns:msdata="urn:schemas-microsoft-com:xml-msdata">
+    <xsd:import namespace="http://www.w3.org/XML/1998/namespace" />
+    <xsd:element name="root" msdata:IsDataSet="true">
+      <xsd:complexType>
+        <xsd:choice maxOccurs="unbounded">
+          <xsd:element name="metadata">
+            <xsd:complexType>
+              <xsd:sequence>
+                <xsd:element name="value" type="xsd:string" minOccurs="0" />
+              </xsd:sequence>
+              <xsd:attribute name="name" use="required" type="xsd:string" />
+              <xsd:attribute name="type" type="xsd:string" />
+              <xsd:attribute name="mimetype" type="xsd:string" />
+              <xsd:attribute ref="xml:space" />
+            </xsd:complexType>
+          </xsd:element>
+          <xsd:element name="assembly">
+            <xsd:complexType>
+              <xsd:attribute name="alias" type="xsd:string" />
+              <xsd:attribute name="name" type="xsd:string" />
+            </xsd:complexType>
+          </xsd:element>
+          <xsd:element name="data">
+            <xsd:complexType>
+              <xsd:sequence>
+                <xsd:element name="value" type="xsd:string" minOccurs="0" msdata:Ordinal="1" />
+                <xsd:element name="comment" type="xsd:string" minOccurs="0" msdata:Ordinal="2" />
+              </xsd:sequence>
+              <xsd:attribute name="name" type="xsd:string" use="required" msdata:Ordinal="1" />
+              <xsd:attribute name="type" type="xsd:string" msdata:Ordinal="3" />
+              <xsd:attribute name="mimetype" type="xsd:string" msdata:Ordinal="4" />
+              <xsd:attribute ref="xml:space" />
+            </xsd:complexType>
+          </xsd:element>
+          <xsd:element name="resheader">
+            <xsd:complexType>
+              <xsd:sequence>
+                <xsd:element name="value" type="xsd:string" minOccurs="0" msdata:Ordinal="1" />
+              </xsd:sequence>
+              <xsd:attribute name="name" type="xsd:string" use="required" />
+            </xsd:complexType>
+          </xsd:element>
+        </xsd:choice>
+      </xsd:complexType>
+    </xsd:element>
+  </xsd:schema>
+  <resheader name="resmimetype">
+    <value>text/microsoft-resx</value>
+  </resheader>
+  <resheader name="version">
+    <value>2.0</value>
+  </resheader>
+  <resheader name="reader">
+    <value>System.Resources.ResXResourceReader, System.Windows.Forms, Version=4.0.0.0, Culture=neutral, PublicKeyToken=b77a5c561934e089</value>
+  </resheader>
+  <resheader name="writer">
+    <value>System.Resources.ResXResourceWriter, System.Windows.Forms, Version=4.0.0.0, Culture=neutral, PublicKeyToken=b77a5c561934e089</value>
+  </resheader>
+`
+
+// rDotNetFormatItem matches .NET composite format items, such as {0}, {1:N2} and {2,-10}, and the escaped braces {{ and }}.
+var rDotNetFormatItem = regexp.MustCompile(`\{\{|\}\}|\{(\d+)(?:,\s*-?\d+)?(?::[^}]*)?\}`)
+
+// FromRESX reads a .NET .resx resource file into messages.
+//
+// The name of each data element is the message ID, its value is Other and its comment is the Description.
+// Resources with a mimetype, or with a type other than System.String, are not strings and are skipped,
+// and so is the designer metadata whose names start with >>. Format items such as {0} and {1:N2} become
+// template fields such as {{.Arg1}} and {{.Arg2}}, and the escaped braces {{ and }} become { and }.
+func FromRESX(inputPath string) ([]message.Message, error) {
+	content, err := os.ReadFile(inputPath)
+	if err != nil {
+		return nil, err
+	}
+	var document resxDocument
+	if err := xml.Unmarshal(content, &document); err != nil {
+		return nil, fmt.Errorf("%s: %w", inputPath, err)
+	}
+
+	var messages []message.Message
+	seen := map[string]bool{}
+	for _, data := range document.Data {
+		if data.MimeType != "" || data.Type != "" && !strings.HasPrefix(data.Type, "System.String") || strings.HasPrefix(data.Name, ">>") {
+			continue
+		}
+		if seen[data.Name] {
+			return nil, fmt.Errorf("%s: duplicate resource name %q", inputPath, data.Name)
+		}
+		seen[data.Name] = true
+		messages = append(messages, message.Message{
+			ID:          data.Name,
+			Description: data.Comment,
+			Other: rDotNetFormatItem.ReplaceAllStringFunc(data.Value, func(item string) string {
+				if item == "{{" || item == "}}" {
+					return item[:1]
+				}
+				index, _ := strconv.Atoi(rDotNetFormatItem.FindStringSubmatch(item)[1])
+				return fmt.Sprintf("{{.Arg%d}}", index+1)
+			}),
+		})
+	}
+
+	if len(messages) == 0 {
+		return nil, nil
+	}
+	sort.Slice(messages, func(i, j int) bool {
+		return messages[i].ID < messages[j].ID
+	})
+	return messages, nil
+}
+
+// ToRESX converts a slice of message.Message objects into a .NET .resx resource file with the standard
+// schema and resheader block. Every message becomes a string data element with its Description as comment.
+// Template fields such as {{.Arg1}} become format items such as {0}, and literal braces are escaped as {{ and }}.
+// Resource files have no plural forms, so plural messages are written with their Other form.
+func ToRESX(messages []message.Message) (string, error) {
+	var sb strings.Builder
+	sb.WriteString(xml.Header)
+	sb.WriteString(resxHeader)
+	for _, msg := range messages {
+		value := resxFormat(msg.Other)
+		sb.WriteString(`  <data name="`)
+		if err := xml.EscapeText(&sb, []byte(msg.ID)); err != nil {
+			return "", err
+		}
+		sb.WriteString("\" xml:space=\"preserve\">\n    <value>")
+		if err := xml.EscapeText(&sb, []byte(value)); err != nil {
+			return "", err
+		}
+		sb.WriteString("</value>\n")
+		if msg.Description != "" {
+			sb.WriteString("    <comment>")
+			if err := xml.EscapeText(&sb, []byte(msg.Description)); err != nil {
+				return "", err
+			}
+			sb.WriteString("</comment>\n")
+		}
+		sb.WriteString("  </data>\n")
+	}
+	sb.WriteString("</root>\n")
+	return sb.String(), nil
+}
+
+// resxFormat converts the template fields of a value into .NET format items and escapes the other braces.
+func resxFormat(value string) string {
+	escape := strings.NewReplacer("{", "{{", "}", "}}")
+	var sb strings.Builder
+	last := 0
+	for _, match := range rTemplateArg.FindAllStringSubmatchIndex(value, -1) {
+		index, _ := strconv.Atoi(value[match[2]:match[3]])
+		if index < 1 {
+			continue
+		}
+		sb.WriteString(escape.Replace(value[last:match[0]]))
+		sb.WriteString(fmt.Sprintf("{%d}", index-1))
+		last = match[1]
+	}
+	sb.WriteString(escape.Replace(value[last:]))
+	return sb.String()
+}
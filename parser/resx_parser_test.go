@@ -0,0 +1,71 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/s-nix/mk2i18n/message"
+	"github.com/stretchr/testify/assert"
+)
+
+const resxContent = `<?xml version="1.0" encoding="utf-8"?>
+<root>
+  <resheader name="resmimetype">
+    <value>text/microsoft-resx</value>
+  </resheader>
+  <data name="Greeting" xml:space="preserve">
+    <value>Hello {0}, you have {1:N0} new messages {{inbox}}</value>
+    <comment>Shown after login</comment>
+  </data>
+  <data name="Title" type="System.String, mscorlib">
+    <value>Start &amp; stop</value>
+  </data>
+  <data name="Logo" type="System.Resources.ResXFileRef, System.Windows.Forms">
+    <value>Resources\logo.png;System.Drawing.Bitmap, System.Drawing</value>
+  </data>
+  <data name="Icon" mimetype="application/x-microsoft.net.object.bytearray.base64">
+    <value>AAABAAEAEBAAAAEAIABoBAAAFgAAACgAAAAQAAAAIAAAAAEAIAAAAAAAAAQAAA==</value>
+  </data>
+  <data name="&gt;&gt;button1.Name" xml:space="preserve">
+    <value>button1</value>
+  </data>
+</root>`
+
+func TestFromRESX(t *testing.T) {
+	messages, err := FromRESX(writeTempFile(t, "Resources_*.resx", resxContent))
+	assert.NoError(t, err)
+
+	expectedMessages := []message.Message{
+		{ID: "Greeting", Description: "Shown after login", Other: "Hello {{.Arg1}}, you have {{.Arg2}} new messages {inbox}"},
+		{ID: "Title", Other: "Start & stop"},
+	}
+	assert.Equal(t, expectedMessages, messages)
+
+	_, err = FromRESX(writeTempFile(t, "Resources_*.resx", `<root><data name="a"><value>1</value></data><data name="a"><value>2</value></data></root>`))
+	assert.ErrorContains(t, err, `duplicate resource name "a"`)
+}
+
+func TestToRESX(t *testing.T) {
+	messages := []message.Message{
+		{ID: "Greeting", Description: "Shown after login", Other: "Hello {{.Arg1}}, you have {{.Arg2}} new messages"},
+		{ID: "Title", Other: "Start & <stop> {now}"},
+	}
+	output, err := ToRESX(messages)
+	assert.NoError(t, err)
+
+	assert.Contains(t, output, `<resheader name="resmimetype">
+    <value>text/microsoft-resx</value>
+  </resheader>`)
+	assert.Contains(t, output, `  <data name="Greeting" xml:space="preserve">
+    <value>Hello {0}, you have {1} new messages</value>
+    <comment>Shown after login</comment>
+  </data>
+  <data name="Title" xml:space="preserve">
+    <value>Start &amp; &lt;stop&gt; {{now}}</value>
+  </data>
+</root>
+`)
+
+	roundTrip, err := FromRESX(writeTempFile(t, "Resources_*.resx", output))
+	assert.NoError(t, err)
+	assert.Equal(t, messages, roundTrip)
+}
@@ -0,0 +1,283 @@
+package parser
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/s-nix/mk2i18n/message"
+)
+
+// appleNoComment is the placeholder comment Xcode writes for strings without a comment.
+const appleNoComment = "No comment provided by engineer."
+
+// stringsScanner reads the tokens of an Apple .strings file.
+type stringsScanner struct {
+	input []rune
+	pos   int
+	line  int
+}
+
+// FromAppleStrings reads an Apple .strings file, such as Localizable.strings, into messages.
+//
+// The file may be UTF-8 or UTF-16 in either byte order, with or without a byte order mark.
+// Each "key" = "value"; pair becomes a message, and the comment right before a pair becomes its Description,
+// except for the "No comment provided by engineer." placeholder written by Xcode.
+// Escapes such as \n, \" and \U00E9 are resolved, and printf placeholders are converted into template fields:
+// %@ becomes {{.Arg1}} and %1$ld becomes {{printf "%d" .Arg1}}.
+func FromAppleStrings(inputPath string) ([]message.Message, error) {
+	content, err := os.ReadFile(inputPath)
+	if err != nil {
+		return nil, err
+	}
+	text, err := decodeAppleStrings(content)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", inputPath, err)
+	}
+
+	scanner := &stringsScanner{input: []rune(text), line: 1}
+	var messages []message.Message
+	lines := map[string]int{}
+	comment := ""
+	for {
+		scanner.skipSpace()
+		if scanner.pos >= len(scanner.input) {
+			break
+		}
+		line := scanner.line
+		if scanner.hasPrefix("/*") || scanner.hasPrefix("//") {
+			comment, err = scanner.readComment()
+			if err != nil {
+				return nil, fmt.Errorf("%s:%d: %w", inputPath, line, err)
+			}
+			continue
+		}
+
+		key, err := scanner.readString()
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", inputPath, scanner.line, err)
+		}
+		value := key
+		scanner.skipSpace()
+		if scanner.hasPrefix("=") {
+			scanner.pos++
+			scanner.skipSpace()
+			value, err = scanner.readString()
+			if err != nil {
+				return nil, fmt.Errorf("%s:%d: %w", inputPath, scanner.line, err)
+			}
+			scanner.skipSpace()
+		}
+		if !scanner.hasPrefix(";") {
+			return nil, fmt.Errorf("%s:%d: expected ';' after %q", inputPath, scanner.line, key)
+		}
+		scanner.pos++
+
+		if first, exists := lines[key]; exists {
+			return nil, fmt.Errorf("%s:%d: duplicate key %q, first defined at line %d", inputPath, line, key, first)
+		}
+		lines[key] = line
+		if comment == appleNoComment {
+			comment = ""
+		}
+		messages = append(messages, message.Message{
+			ID:          key,
+			Description: comment,
+			Other:       printfToTemplate(value),
+		})
+		comment = ""
+	}
+
+	if len(messages) == 0 {
+		return nil, nil
+	}
+	sort.Slice(messages, func(i, j int) bool {
+		return messages[i].ID < messages[j].ID
+	})
+	return messages, nil
+}
+
+// ToAppleStrings converts a slice of message.Message objects into an Apple .strings file.
+// Every message is written as a "key" = "value"; pair with its Description as a comment.
+// Plural messages are written with their Other form; the plural forms themselves belong in a .stringsdict file.
+// Template fields such as {{.Arg1}} become positional object placeholders such as %1$@, and fields formatted
+// with printf keep their conversion, with integers written as %lld: {{printf "%d" .Arg1}} becomes %1$lld.
+// The {{.Count}} field of plural messages becomes an integer argument numbered after the other fields.
+func ToAppleStrings(messages []message.Message) (string, error) {
+	var sb strings.Builder
+	for i, msg := range messages {
+		if i > 0 {
+			sb.WriteString("\n")
+		}
+		if msg.Description != "" {
+			sb.WriteString("/* " + strings.ReplaceAll(msg.Description, "*/", "* /") + " */\n")
+		}
+		sb.WriteString(quoteAppleString(msg.ID) + " = " + quoteAppleString(templateToApplePrintf(msg.Other, appleCountArg(msg))) + ";\n")
+	}
+	return sb.String(), nil
+}
+
+// decodeAppleStrings decodes the content of a .strings file into a string.
+// UTF-16 is detected from the byte order mark, or from the zero bytes of ASCII characters when there is none.
+func decodeAppleStrings(content []byte) (string, error) {
+	var order binary.ByteOrder
+	switch {
+	case bytes.HasPrefix(content, []byte{0xEF, 0xBB, 0xBF}):
+		content = content[3:]
+	case bytes.HasPrefix(content, []byte{0xFF, 0xFE}):
+		order, content = binary.LittleEndian, content[2:]
+	case bytes.HasPrefix(content, []byte{0xFE, 0xFF}):
+		order, content = binary.BigEndian, content[2:]
+	case len(content) >= 2 && content[0] == 0 && content[1] != 0:
+		order = binary.BigEndian
+	case len(content) >= 2 && content[0] != 0 && content[1] == 0:
+		order = binary.LittleEndian
+	}
+
+	if order == nil {
+		if !utf8.Valid(content) {
+			return "", fmt.Errorf("file is neither valid UTF-8 nor UTF-16")
+		}
+		return string(content), nil
+	}
+	if len(content)%2 != 0 {
+		return "", fmt.Errorf("UTF-16 file has an odd number of bytes")
+	}
+	units := make([]uint16, len(content)/2)
+	for i := range units {
+		units[i] = order.Uint16(content[i*2:])
+	}
+	return string(utf16.Decode(units)), nil
+}
+
+// quoteAppleString quotes and escapes a string for a .strings file.
+func quoteAppleString(value string) string {
+	var sb strings.Builder
+	sb.WriteByte('"')
+	for _, r := range value {
+		switch r {
+		case '"':
+			sb.WriteString(`\"`)
+		case '\\':
+			sb.WriteString(`\\`)
+		case '\n':
+			sb.WriteString(`\n`)
+		case '\r':
+			sb.WriteString(`\r`)
+		case '\t':
+			sb.WriteString(`\t`)
+		default:
+			sb.WriteRune(r)
+		}
+	}
+	sb.WriteByte('"')
+	return sb.String()
+}
+
+// hasPrefix reports whether the remaining input starts with prefix.
+func (s *stringsScanner) hasPrefix(prefix string) bool {
+	return strings.HasPrefix(string(s.input[s.pos:min(s.pos+len(prefix), len(s.input))]), prefix)
+}
+
+// skipSpace skips whitespace, counting lines.
+func (s *stringsScanner) skipSpace() {
+	for s.pos < len(s.input) && unicode.IsSpace(s.input[s.pos]) {
+		if s.input[s.pos] == '\n' {
+			s.line++
+		}
+		s.pos++
+	}
+}
+
+// readComment reads a /* block */ or // line comment and returns its trimmed text.
+func (s *stringsScanner) readComment() (string, error) {
+	start := s.pos + 2
+	if s.hasPrefix("//") {
+		for s.pos < len(s.input) && s.input[s.pos] != '\n' {
+			s.pos++
+		}
+		return strings.TrimSpace(string(s.input[start:s.pos])), nil
+	}
+	for s.pos = start; s.pos < len(s.input); s.pos++ {
+		if s.hasPrefix("*/") {
+			s.pos += 2
+			return strings.TrimSpace(string(s.input[start : s.pos-2])), nil
+		}
+		if s.input[s.pos] == '\n' {
+			s.line++
+		}
+	}
+	return "", fmt.Errorf("unterminated comment")
+}
+
+// readString reads a quoted string, resolving its escapes, or an unquoted word.
+func (s *stringsScanner) readString() (string, error) {
+	if s.pos >= len(s.input) {
+		return "", fmt.Errorf("unexpected end of file")
+	}
+	if s.input[s.pos] != '"' {
+		start := s.pos
+		for s.pos < len(s.input) && (unicode.IsLetter(s.input[s.pos]) || unicode.IsDigit(s.input[s.pos]) || strings.ContainsRune("_.-$:/", s.input[s.pos])) {
+			s.pos++
+		}
+		if s.pos == start {
+			return "", fmt.Errorf("unexpected character %q", s.input[s.pos])
+		}
+		return string(s.input[start:s.pos]), nil
+	}
+
+	var units []uint16
+	var sb strings.Builder
+	flush := func() {
+		sb.WriteString(string(utf16.Decode(units)))
+		units = nil
+	}
+	for s.pos++; s.pos < len(s.input); s.pos++ {
+		r := s.input[s.pos]
+		switch {
+		case r == '"':
+			s.pos++
+			flush()
+			return sb.String(), nil
+		case r == '\\' && s.pos+1 < len(s.input):
+			s.pos++
+			escape := s.input[s.pos]
+			if (escape == 'U' || escape == 'u') && s.pos+4 < len(s.input) {
+				if unit, err := strconv.ParseUint(string(s.input[s.pos+1:s.pos+5]), 16, 16); err == nil {
+					// Characters outside the BMP are written as two escaped surrogates.
+					units = append(units, uint16(unit))
+					s.pos += 4
+					continue
+				}
+			}
+			flush()
+			switch escape {
+			case 'n':
+				sb.WriteByte('\n')
+			case 'r':
+				sb.WriteByte('\r')
+			case 't':
+				sb.WriteByte('\t')
+			case '\n':
+				s.line++
+				sb.WriteByte('\n')
+			default:
+				sb.WriteRune(escape)
+			}
+		default:
+			flush()
+			if r == '\n' {
+				s.line++
+			}
+			sb.WriteRune(r)
+		}
+	}
+	return "", fmt.Errorf("unterminated string")
+}
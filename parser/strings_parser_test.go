@@ -0,0 +1,106 @@
+package parser
+
+import (
+	"encoding/binary"
+	"testing"
+	"unicode/utf16"
+
+	"github.com/s-nix/mk2i18n/message"
+	"github.com/stretchr/testify/assert"
+)
+
+const appleStringsContent = `/* Title of the start screen */
+"home.title" = "Welcome, %@!";
+
+/* No comment provided by engineer. */
+"files" = "%ld files in \"%2$@\"";
+// Line comment
+"escapes" = "Tab\tnew\nline \\ caf\U00E9 \UD83D\UDE00";
+unquoted_key = "Unquoted";
+"same";
+`
+
+func TestFromAppleStrings(t *testing.T) {
+	expectedMessages := []message.Message{
+		{ID: "escapes", Description: "Line comment", Other: "Tab\tnew\nline \\ café 😀"},
+		{ID: "files", Other: `{{printf "%d" .Arg1}} files in "{{.Arg2}}"`},
+		{ID: "home.title", Description: "Title of the start screen", Other: "Welcome, {{.Arg1}}!"},
+		{ID: "same", Other: "same"},
+		{ID: "unquoted_key", Other: "Unquoted"},
+	}
+
+	messages, err := FromAppleStrings(writeTempFile(t, "Localizable_*.strings", appleStringsContent))
+	assert.NoError(t, err)
+	assert.Equal(t, expectedMessages, messages)
+
+	for _, order := range []binary.ByteOrder{binary.LittleEndian, binary.BigEndian} {
+		for _, bom := range []bool{true, false} {
+			units := utf16.Encode([]rune(appleStringsContent))
+			if bom {
+				units = append([]uint16{0xFEFF}, units...)
+			}
+			content := make([]byte, len(units)*2)
+			for i, unit := range units {
+				order.PutUint16(content[i*2:], unit)
+			}
+
+			messages, err := FromAppleStrings(writeTempFile(t, "Localizable_*.strings", string(content)))
+			assert.NoError(t, err, "%v, BOM %v", order, bom)
+			assert.Equal(t, expectedMessages, messages, "%v, BOM %v", order, bom)
+		}
+	}
+}
+
+func TestFromAppleStrings_Errors(t *testing.T) {
+	_, err := FromAppleStrings(writeTempFile(t, "Localizable_*.strings", "\"a\" = \"b\"\n\"c\" = \"d\";"))
+	assert.ErrorContains(t, err, ":2: expected ';' after \"a\"")
+
+	_, err = FromAppleStrings(writeTempFile(t, "Localizable_*.strings", "\"a\" = \"b\";\n\"a\" = \"c\";"))
+	assert.ErrorContains(t, err, ":2: duplicate key \"a\", first defined at line 1")
+
+	_, err = FromAppleStrings(writeTempFile(t, "Localizable_*.strings", "/* open"))
+	assert.ErrorContains(t, err, "unterminated comment")
+}
+
+func TestToAppleStrings(t *testing.T) {
+	messages := []message.Message{
+		{ID: "files", Description: "File count */ here", One: "{{.Arg1}} file", Other: "{{.Arg1}} files"},
+		{ID: "quote", Other: "Say \"hi\"\n100%"},
+	}
+	output, err := ToAppleStrings(messages)
+	assert.NoError(t, err)
+
+	expected := `/* File count * / here */
+"files" = "%1$@ files";
+
+"quote" = "Say \"hi\"\n100%";
+`
+	assert.Equal(t, expected, output)
+
+	path := writeTempFile(t, "Localizable_*.strings", output)
+	roundTrip, err := FromAppleStrings(path)
+	assert.NoError(t, err)
+	assert.Equal(t, []message.Message{
+		{ID: "files", Description: "File count * / here", Other: "{{.Arg1}} files"},
+		{ID: "quote", Other: "Say \"hi\"\n100%"},
+	}, roundTrip)
+}
+
+func TestToAppleStrings_PrintfVerbs(t *testing.T) {
+	messages, err := FromAppleStrings(writeTempFile(t, "Localizable_*.strings", `"files" = "%d files in %@, %.1f%% full";`))
+	assert.NoError(t, err)
+	assert.Equal(t, []message.Message{{ID: "files", Other: `{{printf "%d" .Arg1}} files in {{.Arg2}}, {{printf "%.1f" .Arg3}}% full`}}, messages)
+
+	output, err := ToAppleStrings(messages)
+	assert.NoError(t, err)
+	assert.Equal(t, "\"files\" = \"%1$lld files in %2$@, %3$.1f%% full\";\n", output)
+}
+
+func TestToAppleStrings_Count(t *testing.T) {
+	output, err := ToAppleStrings([]message.Message{
+		{ID: "files", One: "{{.Count}} file in {{.Arg1}}", Other: "{{.Count}} files in {{.Arg1}}"},
+		{ID: "songs", One: "{{.Count}} song", Other: "{{.Count}} songs"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "\"files\" = \"%2$lld files in %1$@\";\n\n\"songs\" = \"%1$lld songs\";\n", output)
+}
@@ -0,0 +1,260 @@
+package parser
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/s-nix/mk2i18n/message"
+)
+
+const (
+	// stringsdictHeader is the XML declaration and doctype of property list files.
+	stringsdictHeader = xml.Header + `<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">` + "\n"
+
+	// stringsdictVariable is the name ToStringsdict gives to the plural variable of every message.
+	stringsdictVariable = "count"
+)
+
+// rStringsdictVariable matches the variables of a localized format key, such as %#@count@ or %1$#@count@.
+var rStringsdictVariable = regexp.MustCompile(`%(?:\d+\$)?#@([^@]+)@`)
+
+// FromStringsdict reads an Apple .stringsdict property list into plural messages.
+//
+// Each top-level key is a message ID. The NSStringLocalizedFormatKey of the entry may use one
+// NSStringPluralRuleType variable, such as %#@count@, whose zero, one, two, few, many and other strings
+// are substituted into the format to give the plural forms. A comment right before a key becomes its Description.
+// Entries without a localized format key, such as variable width rules, are skipped.
+// Printf placeholders are converted into template fields, such as {{printf "%d" .Arg1}} for %d.
+func FromStringsdict(inputPath string) ([]message.Message, error) {
+	content, err := os.ReadFile(inputPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var messages []message.Message
+	decoder := xml.NewDecoder(bytes.NewReader(content))
+	depth := 0
+	comment := ""
+	key := ""
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch tt := token.(type) {
+		case xml.Comment:
+			if depth == 2 {
+				comment = strings.TrimSpace(string(tt))
+			}
+			continue
+		case xml.EndElement:
+			depth--
+			continue
+		case xml.StartElement:
+			if depth < 2 {
+				depth++
+				continue
+			}
+		default:
+			continue
+		}
+
+		start := token.(xml.StartElement)
+		if start.Name.Local == "key" {
+			var text xmlText
+			if err := decoder.DecodeElement(&text, &start); err != nil {
+				return nil, err
+			}
+			key = string(text)
+			continue
+		}
+		value, err := decodePlistValue(decoder, start)
+		if err != nil {
+			return nil, err
+		}
+		entry, ok := value.(map[string]any)
+		if !ok || key == "" {
+			comment, key = "", ""
+			continue
+		}
+		msg, ok, err := stringsdictMessage(key, entry)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", inputPath, err)
+		}
+		if ok {
+			msg.Description = comment
+			messages = append(messages, msg)
+		}
+		comment, key = "", ""
+	}
+
+	if len(messages) == 0 {
+		return nil, nil
+	}
+	sort.Slice(messages, func(i, j int) bool {
+		return messages[i].ID < messages[j].ID
+	})
+	return messages, nil
+}
+
+// ToStringsdict converts the plural messages of a slice of message.Message objects into an Apple .stringsdict property list.
+// Every plural message gets the localized format %#@count@ with one plural rule variable holding its plural forms,
+// and its Description as a comment. Messages that are not plural belong in a .strings file and are left out.
+// Template fields such as {{.Arg1}} become positional object placeholders such as %1$@, and fields formatted
+// with printf keep their conversion, such as %1$lld for {{printf "%d" .Arg1}}. The value type of the plural
+// variable is the conversion of the first argument, or d when the plural forms do not format it with printf.
+// When the forms use the {{.Count}} field, it becomes the integer argument of the plural variable, numbered
+// after the other fields, as in %2$#@count@ with %2$lld.
+func ToStringsdict(messages []message.Message) (string, error) {
+	var sb strings.Builder
+	sb.WriteString(stringsdictHeader)
+	sb.WriteString("<plist version=\"1.0\">\n<dict>\n")
+	for _, msg := range messages {
+		if !msg.IsPlural() {
+			continue
+		}
+		if msg.Description != "" {
+			sb.WriteString("\t<!-- " + strings.ReplaceAll(msg.Description, "--", "- -") + " -->\n")
+		}
+		writePlistElement(&sb, 1, "key", msg.ID)
+		sb.WriteString("\t<dict>\n")
+		writePlistElement(&sb, 2, "key", "NSStringLocalizedFormatKey")
+		count := appleCountArg(msg)
+		format, valueType := "%#@"+stringsdictVariable+"@", stringsdictValueType(msg)
+		if hasTemplateCount(msg) {
+			valueType = "lld"
+			if count > 1 {
+				format = "%" + strconv.Itoa(count) + "$#@" + stringsdictVariable + "@"
+			}
+		}
+		writePlistElement(&sb, 2, "string", format)
+		writePlistElement(&sb, 2, "key", stringsdictVariable)
+		sb.WriteString("\t\t<dict>\n")
+		writePlistElement(&sb, 3, "key", "NSStringFormatSpecTypeKey")
+		writePlistElement(&sb, 3, "string", "NSStringPluralRuleType")
+		writePlistElement(&sb, 3, "key", "NSStringFormatValueTypeKey")
+		writePlistElement(&sb, 3, "string", valueType)
+		for _, category := range message.PluralCategories {
+			if form := msg.PluralForm(category); form != "" {
+				writePlistElement(&sb, 3, "key", category)
+				writePlistElement(&sb, 3, "string", templateToApplePrintf(form, count))
+			}
+		}
+		sb.WriteString("\t\t</dict>\n")
+		sb.WriteString("\t</dict>\n")
+	}
+	sb.WriteString("</dict>\n</plist>\n")
+	return sb.String(), nil
+}
+
+// stringsdictValueType returns the value type of the plural variable of a message,
+// which is the conversion of the first argument in its plural forms.
+func stringsdictValueType(msg message.Message) string {
+	for _, category := range message.PluralCategories {
+		if conversion, ok := templateArgConversion(msg.PluralForm(category), 1, "ll"); ok {
+			return conversion
+		}
+	}
+	return "d"
+}
+
+// stringsdictMessage builds a plural message from a top-level .stringsdict entry.
+// It reports false for entries without a localized format key.
+func stringsdictMessage(id string, entry map[string]any) (message.Message, bool, error) {
+	format, ok := entry["NSStringLocalizedFormatKey"].(string)
+	if !ok {
+		return message.Message{}, false, nil
+	}
+	msg := message.Message{ID: id}
+	variables := rStringsdictVariable.FindAllStringSubmatch(format, -1)
+	switch len(variables) {
+	case 0:
+		msg.Other = printfToTemplate(format)
+		return msg, true, nil
+	case 1:
+	default:
+		return msg, false, fmt.Errorf("%q uses %d plural variables, only one is supported", id, len(variables))
+	}
+
+	variable, ok := entry[variables[0][1]].(map[string]any)
+	if !ok {
+		return msg, false, fmt.Errorf("%q has no definition for variable %q", id, variables[0][1])
+	}
+	if ruleType, _ := variable["NSStringFormatSpecTypeKey"].(string); ruleType != "NSStringPluralRuleType" {
+		return msg, false, fmt.Errorf("%q uses unsupported rule type %q for variable %q", id, ruleType, variables[0][1])
+	}
+	for _, category := range message.PluralCategories {
+		if form, ok := variable[category].(string); ok {
+			msg.SetPluralForm(category, printfToTemplate(strings.Replace(format, variables[0][0], form, 1)))
+		}
+	}
+	if msg.Other == "" {
+		return msg, false, fmt.Errorf("%q has no other form for variable %q", id, variables[0][1])
+	}
+	return msg, true, nil
+}
+
+// decodePlistValue decodes the property list value that starts with the given element.
+// A dict becomes a map[string]any, an array a []any and a string a string; other values are skipped and give nil.
+func decodePlistValue(decoder *xml.Decoder, start xml.StartElement) (any, error) {
+	switch start.Name.Local {
+	case "string":
+		var text xmlText
+		if err := decoder.DecodeElement(&text, &start); err != nil {
+			return nil, err
+		}
+		return string(text), nil
+	case "dict", "array":
+		dict := map[string]any{}
+		var array []any
+		key := ""
+		for {
+			token, err := decoder.Token()
+			if err != nil {
+				return nil, err
+			}
+			switch tt := token.(type) {
+			case xml.StartElement:
+				if tt.Name.Local == "key" {
+					var text xmlText
+					if err := decoder.DecodeElement(&text, &tt); err != nil {
+						return nil, err
+					}
+					key = string(text)
+					continue
+				}
+				value, err := decodePlistValue(decoder, tt)
+				if err != nil {
+					return nil, err
+				}
+				array = append(array, value)
+				dict[key] = value
+			case xml.EndElement:
+				if start.Name.Local == "array" {
+					return array, nil
+				}
+				return dict, nil
+			}
+		}
+	default:
+		return nil, decoder.Skip()
+	}
+}
+
+// writePlistElement writes an indented element with escaped text content.
+func writePlistElement(sb *strings.Builder, indent int, name string, text string) {
+	sb.WriteString(strings.Repeat("\t", indent) + "<" + name + ">")
+	_ = xml.EscapeText(sb, []byte(text))
+	sb.WriteString("</" + name + ">\n")
+}
@@ -0,0 +1,142 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/s-nix/mk2i18n/message"
+	"github.com/stretchr/testify/assert"
+)
+
+const stringsdictContent = `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<!-- Number of files in a folder -->
+	<key>files</key>
+	<dict>
+		<key>NSStringLocalizedFormatKey</key>
+		<string>%1$@ has %#@files@</string>
+		<key>files</key>
+		<dict>
+			<key>NSStringFormatSpecTypeKey</key>
+			<string>NSStringPluralRuleType</string>
+			<key>NSStringFormatValueTypeKey</key>
+			<string>d</string>
+			<key>zero</key>
+			<string>no files</string>
+			<key>one</key>
+			<string>%2$d file</string>
+			<key>other</key>
+			<string>%2$d files</string>
+		</dict>
+	</dict>
+	<key>width</key>
+	<dict>
+		<key>NSStringVariableWidthRuleType</key>
+		<dict>
+			<key>100</key>
+			<string>Short</string>
+		</dict>
+	</dict>
+</dict>
+</plist>
+`
+
+func TestFromStringsdict(t *testing.T) {
+	messages, err := FromStringsdict(writeTempFile(t, "Localizable_*.stringsdict", stringsdictContent))
+	assert.NoError(t, err)
+
+	expectedMessages := []message.Message{
+		{
+			ID:          "files",
+			Description: "Number of files in a folder",
+			Zero:        "{{.Arg1}} has no files",
+			One:         `{{.Arg1}} has {{printf "%d" .Arg2}} file`,
+			Other:       `{{.Arg1}} has {{printf "%d" .Arg2}} files`,
+		},
+	}
+	assert.Equal(t, expectedMessages, messages)
+}
+
+func TestFromStringsdict_MultipleVariables(t *testing.T) {
+	content := `<plist version="1.0"><dict>
+	<key>both</key>
+	<dict>
+		<key>NSStringLocalizedFormatKey</key>
+		<string>%#@files@ in %#@folders@</string>
+	</dict>
+</dict></plist>`
+
+	_, err := FromStringsdict(writeTempFile(t, "Localizable_*.stringsdict", content))
+	assert.ErrorContains(t, err, `"both" uses 2 plural variables, only one is supported`)
+}
+
+func TestToStringsdict(t *testing.T) {
+	messages := []message.Message{
+		{ID: "greeting", Other: "Hello"},
+		{ID: "items", Description: "Item count", One: "{{.Arg1}} item", Other: "{{.Arg1}} items & more"},
+	}
+	output, err := ToStringsdict(messages)
+	assert.NoError(t, err)
+
+	expected := `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<!-- Item count -->
+	<key>items</key>
+	<dict>
+		<key>NSStringLocalizedFormatKey</key>
+		<string>%#@count@</string>
+		<key>count</key>
+		<dict>
+			<key>NSStringFormatSpecTypeKey</key>
+			<string>NSStringPluralRuleType</string>
+			<key>NSStringFormatValueTypeKey</key>
+			<string>d</string>
+			<key>one</key>
+			<string>%1$@ item</string>
+			<key>other</key>
+			<string>%1$@ items &amp; more</string>
+		</dict>
+	</dict>
+</dict>
+</plist>
+`
+	assert.Equal(t, expected, output)
+
+	roundTrip, err := FromStringsdict(writeTempFile(t, "Localizable_*.stringsdict", output))
+	assert.NoError(t, err)
+	assert.Equal(t, messages[1:], roundTrip)
+}
+
+func TestToStringsdict_PrintfVerbs(t *testing.T) {
+	messages := []message.Message{
+		{ID: "files", One: `{{printf "%d" .Arg1}} file in {{.Arg2}}`, Other: `{{printf "%d" .Arg1}} files in {{.Arg2}}`},
+	}
+	output, err := ToStringsdict(messages)
+	assert.NoError(t, err)
+	assert.Contains(t, output, "<key>NSStringFormatValueTypeKey</key>\n\t\t\t<string>lld</string>")
+	assert.Contains(t, output, "<key>one</key>\n\t\t\t<string>%1$lld file in %2$@</string>")
+	assert.Contains(t, output, "<key>other</key>\n\t\t\t<string>%1$lld files in %2$@</string>")
+
+	roundTrip, err := FromStringsdict(writeTempFile(t, "Localizable_*.stringsdict", output))
+	assert.NoError(t, err)
+	assert.Equal(t, messages, roundTrip)
+}
+
+func TestToStringsdict_Count(t *testing.T) {
+	output, err := ToStringsdict([]message.Message{
+		{ID: "files", One: "{{.Count}} file in {{.Arg1}}", Other: "{{.Count}} files in {{.Arg1}}"},
+	})
+	assert.NoError(t, err)
+	assert.Contains(t, output, "<key>NSStringLocalizedFormatKey</key>\n\t\t<string>%2$#@count@</string>")
+	assert.Contains(t, output, "<key>NSStringFormatValueTypeKey</key>\n\t\t\t<string>lld</string>")
+	assert.Contains(t, output, "<key>other</key>\n\t\t\t<string>%2$lld files in %1$@</string>")
+
+	roundTrip, err := FromStringsdict(writeTempFile(t, "Localizable_*.stringsdict", output))
+	assert.NoError(t, err)
+	assert.Equal(t, []message.Message{
+		{ID: "files", One: `{{printf "%d" .Arg2}} file in {{.Arg1}}`, Other: `{{printf "%d" .Arg2}} files in {{.Arg1}}`},
+	}, roundTrip)
+}
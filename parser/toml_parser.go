@@ -2,6 +2,7 @@ package parser
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/BurntSushi/toml"
 	"github.com/s-nix/mk2i18n/message"
@@ -19,14 +20,34 @@ func ToTOML(messages []message.Message) (string, error) {
 	return result, nil
 }
 
+// ToNestedTOML converts a slice of message.Message objects into plain nested TOML rather than go-i18n
+// message objects. See UnflattenMessages for how the messages are nested.
+func ToNestedTOML(messages []message.Message) (string, error) {
+	data, err := UnflattenMessages(messages)
+	if err != nil {
+		return "", err
+	}
+	var sb strings.Builder
+	if err := toml.NewEncoder(&sb).Encode(data); err != nil {
+		return "", err
+	}
+	return sb.String(), nil
+}
+
+// FromTOML reads a TOML file and flattens it into messages.
 func FromTOML(inputPath string) ([]message.Message, error) {
+	return FromTOMLWithOptions(inputPath, FlattenOptions{})
+}
+
+// FromTOMLWithOptions reads a TOML file and flattens it into messages using the given options.
+func FromTOMLWithOptions(inputPath string, opts FlattenOptions) ([]message.Message, error) {
 	var messages []message.Message
 	var data map[string]any
 	_, err := toml.DecodeFile(inputPath, &data)
 	if err != nil {
 		return nil, err
 	}
-	FlattenDataToMessages(data, &messages, "")
+	FlattenDataToMessagesWithOptions(data, &messages, "", opts)
 	if len(messages) == 0 {
 		return nil, fmt.Errorf("no messages found in TOML file")
 	}
@@ -102,12 +102,9 @@ other = 1
 			Other: "nested2",
 		},
 		{
-			ID:    "farewell.other",
-			Other: "1",
-		},
-		{
-			ID:    "farewell.description",
-			Other: "A farewell message",
+			ID:          "farewell",
+			Description: "A farewell message",
+			Other:       "1",
 		},
 	}
 
@@ -119,7 +116,7 @@ other = 1
 	for _, expectedMsg := range expectedMessages {
 		found := false
 		for _, msg := range messages {
-			if msg.ID == expectedMsg.ID && msg.Other == expectedMsg.Other {
+			if msg.ID == expectedMsg.ID && msg.Other == expectedMsg.Other && msg.Description == expectedMsg.Description {
 				found = true
 				break
 			}
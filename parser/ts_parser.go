@@ -0,0 +1,226 @@
+package parser
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/s-nix/mk2i18n/message"
+)
+
+// TSOptions configures how Qt Linguist .ts files are read and written.
+type TSOptions struct {
+	// IncludeUnfinished uses translations of the unfinished type when reading.
+	// By default, like lrelease, unfinished translations are treated as untranslated.
+	IncludeUnfinished bool
+
+	// Locale is the language written to the file, such as de or pt_BR. It selects the number and order of the
+	// numerus forms. When reading, it is used for files without a language attribute. Defaults to English rules.
+	Locale string
+}
+
+type tsDocument struct {
+	XMLName        xml.Name    `xml:"TS"`
+	Version        string      `xml:"version,attr"`
+	Language       string      `xml:"language,attr,omitempty"`
+	SourceLanguage string      `xml:"sourcelanguage,attr,omitempty"`
+	Contexts       []tsContext `xml:"context"`
+}
+
+type tsContext struct {
+	Name     string      `xml:"name"`
+	Messages []tsMessage `xml:"message"`
+}
+
+type tsMessage struct {
+	ID           string        `xml:"id,attr,omitempty"`
+	Numerus      string        `xml:"numerus,attr,omitempty"`
+	Source       string        `xml:"source"`
+	Comment      string        `xml:"comment,omitempty"`
+	ExtraComment string        `xml:"extracomment,omitempty"`
+	Translation  tsTranslation `xml:"translation"`
+}
+
+type tsTranslation struct {
+	Type         string   `xml:"type,attr,omitempty"`
+	Text         string   `xml:",chardata"`
+	NumerusForms []string `xml:"numerusform"`
+}
+
+// rQtPlaceholder matches the placeholders of QString::arg and QObject::tr, such as %1, %L2 and %n.
+var rQtPlaceholder = regexp.MustCompile(`%L?(n|\d{1,2})`)
+
+// FromTS reads the finished translations of a Qt Linguist .ts file into messages.
+func FromTS(inputPath string) ([]message.Message, error) {
+	return FromTSWithOptions(inputPath, TSOptions{})
+}
+
+// FromTSWithOptions reads a Qt Linguist .ts file into messages.
+//
+// The message ID is the context name and the source text, or the id attribute of id-based messages, joined with
+// a dot, such as MainWindow.Open file. The comment and extracomment become the Description. Messages with
+// numerus="yes" map their numerus forms to the plural forms of the language of the file.
+// Unfinished translations are treated as untranslated unless IncludeUnfinished is set, untranslated messages fall
+// back to the source text, and vanished and obsolete messages are skipped.
+// Placeholders become template fields: %1 becomes {{.Arg1}} and %n becomes {{.Count}}.
+func FromTSWithOptions(inputPath string, opts TSOptions) ([]message.Message, error) {
+	content, err := os.ReadFile(inputPath)
+	if err != nil {
+		return nil, err
+	}
+	var document tsDocument
+	if err := xml.Unmarshal(content, &document); err != nil {
+		return nil, fmt.Errorf("%s: %w", inputPath, err)
+	}
+	locale := document.Language
+	if locale == "" {
+		locale = opts.Locale
+	}
+
+	var messages []message.Message
+	seen := map[string]bool{}
+	for _, context := range document.Contexts {
+		for _, tsMsg := range context.Messages {
+			translation := tsMsg.Translation
+			if translation.Type == "vanished" || translation.Type == "obsolete" {
+				continue
+			}
+			key := tsMsg.Source
+			if tsMsg.ID != "" {
+				key = tsMsg.ID
+			}
+			if context.Name != "" {
+				key = context.Name + "." + key
+			}
+			if seen[key] {
+				return nil, fmt.Errorf("%s: duplicate message ID %q", inputPath, key)
+			}
+			seen[key] = true
+
+			var descriptions []string
+			for _, comment := range []string{tsMsg.Comment, tsMsg.ExtraComment} {
+				if comment = strings.TrimSpace(comment); comment != "" {
+					descriptions = append(descriptions, comment)
+				}
+			}
+			msg := message.Message{ID: key, Description: strings.Join(descriptions, "\n"), Other: tsMsg.Source}
+
+			translated := translation.Type != "unfinished" || opts.IncludeUnfinished
+			if tsMsg.Numerus != "yes" {
+				if translated && translation.Text != "" {
+					msg.Other = translation.Text
+				}
+			} else if translated && strings.Join(translation.NumerusForms, "") != "" {
+				categories := pluralCategoriesFor(locale, len(translation.NumerusForms))
+				if categories == nil {
+					return nil, fmt.Errorf("%s: unsupported numerus form count %d in %q", inputPath, len(translation.NumerusForms), key)
+				}
+				msg.Other = ""
+				for index, form := range translation.NumerusForms {
+					msg.SetPluralForm(categories[index], form)
+				}
+				fillOtherForm(&msg, categories)
+			}
+			for _, category := range message.PluralCategories {
+				msg.SetPluralForm(category, qtToTemplate(msg.PluralForm(category)))
+			}
+			messages = append(messages, msg)
+		}
+	}
+
+	if len(messages) == 0 {
+		return nil, nil
+	}
+	sort.Slice(messages, func(i, j int) bool {
+		return messages[i].ID < messages[j].ID
+	})
+	return messages, nil
+}
+
+// TSLanguage returns the language attribute of a .ts file, or an empty string when it has none.
+func TSLanguage(inputPath string) (string, error) {
+	content, err := os.ReadFile(inputPath)
+	if err != nil {
+		return "", err
+	}
+	var document tsDocument
+	if err := xml.Unmarshal(content, &document); err != nil {
+		return "", fmt.Errorf("%s: %w", inputPath, err)
+	}
+	return document.Language, nil
+}
+
+// ToTS converts a slice of message.Message objects into a Qt Linguist .ts file with English plural forms.
+func ToTS(messages []message.Message) (string, error) {
+	return ToTSWithOptions(messages, TSOptions{})
+}
+
+// ToTSWithOptions converts a slice of message.Message objects into a Qt Linguist .ts file using the given options.
+//
+// The part of the message ID before the first dot is the context name and the rest is the source text,
+// so home.title becomes the source title in the context home; IDs without a dot go to a context without a name.
+// The Description is written as extracomment and Other as translation. Plural messages are written with
+// numerus="yes" and one numerus form per plural form of the Locale, falling back to Other for missing forms.
+// Template fields become placeholders: {{.Arg1}} becomes %1 and {{.Count}} becomes %n.
+func ToTSWithOptions(messages []message.Message, opts TSOptions) (string, error) {
+	rule := pluralRuleForLocale(opts.Locale)
+	document := tsDocument{Version: "2.1", Language: opts.Locale}
+	contexts := map[string]int{}
+	for _, msg := range messages {
+		name, source, found := strings.Cut(msg.ID, ".")
+		if !found {
+			name, source = "", msg.ID
+		}
+		index, ok := contexts[name]
+		if !ok {
+			index = len(document.Contexts)
+			contexts[name] = index
+			document.Contexts = append(document.Contexts, tsContext{Name: name})
+		}
+
+		tsMsg := tsMessage{Source: source, ExtraComment: msg.Description}
+		if !msg.IsPlural() {
+			tsMsg.Translation.Text = templateToQt(msg.Other)
+		} else {
+			tsMsg.Numerus = "yes"
+			for _, category := range rule.categories {
+				form := msg.PluralForm(category)
+				if form == "" {
+					form = msg.Other
+				}
+				tsMsg.Translation.NumerusForms = append(tsMsg.Translation.NumerusForms, templateToQt(form))
+			}
+		}
+		document.Contexts[index].Messages = append(document.Contexts[index].Messages, tsMsg)
+	}
+
+	content, err := xml.MarshalIndent(document, "", "    ")
+	if err != nil {
+		return "", err
+	}
+	return xml.Header + "<!DOCTYPE TS>\n" + string(content) + "\n", nil
+}
+
+// qtToTemplate converts Qt placeholders into template fields.
+func qtToTemplate(value string) string {
+	return rQtPlaceholder.ReplaceAllStringFunc(value, func(placeholder string) string {
+		if strings.HasSuffix(placeholder, "n") {
+			return "{{.Count}}"
+		}
+		return "{{.Arg" + strings.TrimLeft(placeholder, "%L") + "}}"
+	})
+}
+
+// templateToQt converts the template fields written by qtToTemplate back into Qt placeholders.
+func templateToQt(value string) string {
+	value = rTemplateArg.ReplaceAllString(value, "%$1")
+	return rTemplateField.ReplaceAllStringFunc(value, func(field string) string {
+		if rTemplateField.FindStringSubmatch(field)[1] == "Count" {
+			return "%n"
+		}
+		return field
+	})
+}
@@ -0,0 +1,114 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/s-nix/mk2i18n/message"
+	"github.com/stretchr/testify/assert"
+)
+
+const tsContent = `<?xml version="1.0" encoding="utf-8"?>
+<!DOCTYPE TS>
+<TS version="2.1" language="ru_RU">
+<context>
+    <name>MainWindow</name>
+    <message>
+        <location filename="../mainwindow.cpp" line="42"/>
+        <source>Open %1</source>
+        <comment>File menu</comment>
+        <extracomment>%1 is the file name</extracomment>
+        <translation>Открыть %1</translation>
+    </message>
+    <message numerus="yes">
+        <source>%n file(s)</source>
+        <translation>
+            <numerusform>%n файл</numerusform>
+            <numerusform>%n файла</numerusform>
+            <numerusform>%n файлов</numerusform>
+        </translation>
+    </message>
+    <message>
+        <source>Save</source>
+        <translation type="unfinished">Сохр</translation>
+    </message>
+    <message>
+        <source>Quit</source>
+        <translation type="vanished">Выход</translation>
+    </message>
+</context>
+<context>
+    <name>Dialog</name>
+    <message id="dialog.ok">
+        <source>OK</source>
+        <translation>Хорошо</translation>
+    </message>
+</context>
+</TS>`
+
+func TestFromTS(t *testing.T) {
+	path := writeTempFile(t, "app_*.ts", tsContent)
+	messages, err := FromTS(path)
+	assert.NoError(t, err)
+
+	expectedMessages := []message.Message{
+		{ID: "Dialog.dialog.ok", Other: "Хорошо"},
+		{ID: "MainWindow.%n file(s)", One: "{{.Count}} файл", Few: "{{.Count}} файла", Many: "{{.Count}} файлов", Other: "{{.Count}} файлов"},
+		{ID: "MainWindow.Open %1", Description: "File menu\n%1 is the file name", Other: "Открыть {{.Arg1}}"},
+		{ID: "MainWindow.Save", Other: "Save"},
+	}
+	assert.Equal(t, expectedMessages, messages)
+
+	messages, err = FromTSWithOptions(path, TSOptions{IncludeUnfinished: true})
+	assert.NoError(t, err)
+	assert.Equal(t, "Сохр", messages[3].Other)
+
+	language, err := TSLanguage(path)
+	assert.NoError(t, err)
+	assert.Equal(t, "ru_RU", language)
+
+	_, err = FromTS(writeTempFile(t, "app_*.ts", `<TS><context><name>A</name><message><source>x</source></message><message><source>x</source></message></context></TS>`))
+	assert.ErrorContains(t, err, `duplicate message ID "A.x"`)
+}
+
+func TestToTS(t *testing.T) {
+	messages := []message.Message{
+		{ID: "MainWindow.files", Description: "File count", One: "{{.Count}} Datei", Other: "{{.Count}} Dateien"},
+		{ID: "MainWindow.open", Other: "Öffne {{.Arg1}} & mehr"},
+		{ID: "title", Other: "Titel"},
+	}
+	output, err := ToTSWithOptions(messages, TSOptions{Locale: "de"})
+	assert.NoError(t, err)
+
+	expected := `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE TS>
+<TS version="2.1" language="de">
+    <context>
+        <name>MainWindow</name>
+        <message numerus="yes">
+            <source>files</source>
+            <extracomment>File count</extracomment>
+            <translation>
+                <numerusform>%n Datei</numerusform>
+                <numerusform>%n Dateien</numerusform>
+            </translation>
+        </message>
+        <message>
+            <source>open</source>
+            <translation>Öffne %1 &amp; mehr</translation>
+        </message>
+    </context>
+    <context>
+        <name></name>
+        <message>
+            <source>title</source>
+            <translation>Titel</translation>
+        </message>
+    </context>
+</TS>
+`
+	assert.Equal(t, expected, output)
+
+	roundTrip, err := FromTS(writeTempFile(t, "app_*.ts", output))
+	assert.NoError(t, err)
+	assert.Equal(t, messages, roundTrip)
+}
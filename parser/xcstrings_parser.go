@@ -0,0 +1,318 @@
+package parser
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/s-nix/mk2i18n/message"
+)
+
+// XCStringsOptions configures how Xcode string catalogs are read and written.
+type XCStringsOptions struct {
+	// Locale is the locale read from or written to the catalog. Defaults to the source language of the catalog.
+	Locale string
+
+	// SourceLanguage is the source language of catalogs created from scratch. Defaults to "en".
+	SourceLanguage string
+
+	// IncludeNeedsReview uses translations in the needs_review state instead of treating them as untranslated.
+	IncludeNeedsReview bool
+}
+
+// FromXCStrings reads the source language of an Xcode string catalog (.xcstrings) into messages.
+func FromXCStrings(inputPath string) ([]message.Message, error) {
+	return FromXCStringsWithOptions(inputPath, XCStringsOptions{})
+}
+
+// FromXCStringsWithOptions reads one locale of an Xcode string catalog (.xcstrings) into messages.
+//
+// Each key of the catalog is a message ID and its comment is the Description. The string unit of the
+// locale becomes Other, and plural variations become the plural forms. Translations that are not in the
+// translated state, or in the needs_review state with IncludeNeedsReview, are treated as untranslated and
+// fall back to the source language, and then to the key itself, like untranslated PO entries fall back to msgid.
+// Strings marked shouldTranslate false or with the stale extraction state are skipped.
+// Printf placeholders are converted into template fields: %@ becomes {{.Arg1}} and %lld becomes {{printf "%d" .Arg1}}.
+func FromXCStringsWithOptions(inputPath string, opts XCStringsOptions) ([]message.Message, error) {
+	catalog, err := readXCStrings(inputPath)
+	if err != nil {
+		return nil, err
+	}
+	sourceLanguage, _ := catalog["sourceLanguage"].(string)
+	locale := opts.Locale
+	if locale == "" {
+		locale = sourceLanguage
+	}
+
+	var messages []message.Message
+	for id, value := range jsonObject(catalog["strings"]) {
+		entry := jsonObject(value)
+		if entry["shouldTranslate"] == false || entry["extractionState"] == "stale" {
+			continue
+		}
+		msg := message.Message{ID: id, Other: id}
+		if comment, ok := entry["comment"].(string); ok {
+			msg.Description = comment
+		}
+
+		localizations := jsonObject(entry["localizations"])
+		localization := jsonObject(localizations[locale])
+		if !xcstringsTranslated(localization, locale == sourceLanguage, opts) {
+			localization = jsonObject(localizations[sourceLanguage])
+		}
+		if _, ok := localization["substitutions"]; ok {
+			return nil, fmt.Errorf("%s: %q uses substitutions, which are not supported", inputPath, id)
+		}
+		variations := jsonObject(localization["variations"])
+		for kind := range variations {
+			if kind != "plural" {
+				return nil, fmt.Errorf("%s: %q uses %s variations, which are not supported", inputPath, id, kind)
+			}
+		}
+
+		if plural := jsonObject(variations["plural"]); plural != nil {
+			msg.Other = ""
+			for category, variation := range plural {
+				unit := jsonObject(jsonObject(variation)["stringUnit"])
+				text, _ := unit["value"].(string)
+				if !msg.SetPluralForm(category, printfToTemplate(text)) {
+					return nil, fmt.Errorf("%s: unknown plural category %q in %q", inputPath, category, id)
+				}
+			}
+		} else if text, ok := jsonObject(localization["stringUnit"])["value"].(string); ok {
+			msg.Other = printfToTemplate(text)
+		}
+		messages = append(messages, msg)
+	}
+
+	if len(messages) == 0 {
+		return nil, nil
+	}
+	sort.Slice(messages, func(i, j int) bool {
+		return messages[i].ID < messages[j].ID
+	})
+	return messages, nil
+}
+
+// XCStringsLocales returns the source language and every locale with localizations in an Xcode string catalog, sorted.
+func XCStringsLocales(inputPath string) ([]string, error) {
+	catalog, err := readXCStrings(inputPath)
+	if err != nil {
+		return nil, err
+	}
+	found := map[string]bool{}
+	if sourceLanguage, ok := catalog["sourceLanguage"].(string); ok && sourceLanguage != "" {
+		found[sourceLanguage] = true
+	}
+	for _, entry := range jsonObject(catalog["strings"]) {
+		for locale := range jsonObject(jsonObject(entry)["localizations"]) {
+			found[locale] = true
+		}
+	}
+	var locales []string
+	for locale := range found {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales)
+	return locales, nil
+}
+
+// ToXCStrings merges a slice of message.Message objects into the source language of an Xcode string catalog and returns the new catalog.
+func ToXCStrings(messages []message.Message, catalog []byte) (string, error) {
+	return ToXCStringsWithOptions(messages, catalog, XCStringsOptions{})
+}
+
+// ToXCStringsWithOptions merges a slice of message.Message objects into an Xcode string catalog and returns the new catalog.
+//
+// The catalog is the content of the existing .xcstrings file, or nil to start a new catalog. Only the localization of
+// the target locale of each message is replaced, so other locales and strings that are not in messages are kept.
+// Messages that are not in the catalog are added with the manual extraction state, and descriptions replace comments.
+// Written string units are in the translated state, unless their value did not change.
+// Template fields such as {{.Arg1}} become positional object placeholders such as %1$@, and fields formatted
+// with printf keep their conversion, with integers written as %lld like Xcode does: {{printf "%d" .Arg1}} becomes %1$lld.
+// The {{.Count}} field of plural messages becomes an integer argument numbered after the other fields.
+func ToXCStringsWithOptions(messages []message.Message, catalog []byte, opts XCStringsOptions) (string, error) {
+	document := map[string]any{}
+	if len(bytes.TrimSpace(catalog)) > 0 {
+		decoder := json.NewDecoder(bytes.NewReader(catalog))
+		decoder.UseNumber()
+		if err := decoder.Decode(&document); err != nil {
+			return "", err
+		}
+	}
+	if _, ok := document["sourceLanguage"].(string); !ok {
+		document["sourceLanguage"] = opts.SourceLanguage
+		if opts.SourceLanguage == "" {
+			document["sourceLanguage"] = "en"
+		}
+	}
+	if _, ok := document["version"]; !ok {
+		document["version"] = "1.0"
+	}
+	locale := opts.Locale
+	if locale == "" {
+		locale = document["sourceLanguage"].(string)
+	}
+
+	strs := jsonObject(document["strings"])
+	if strs == nil {
+		strs = map[string]any{}
+		document["strings"] = strs
+	}
+	for _, msg := range messages {
+		entry := jsonObject(strs[msg.ID])
+		if entry == nil {
+			entry = map[string]any{"extractionState": "manual"}
+			strs[msg.ID] = entry
+		}
+		if msg.Description != "" {
+			entry["comment"] = msg.Description
+		}
+		localizations := jsonObject(entry["localizations"])
+		if localizations == nil {
+			localizations = map[string]any{}
+			entry["localizations"] = localizations
+		}
+		localization := jsonObject(localizations[locale])
+		if localization == nil {
+			localization = map[string]any{}
+			localizations[locale] = localization
+		}
+
+		if !msg.IsPlural() {
+			delete(localization, "variations")
+			localization["stringUnit"] = xcstringsUnit(jsonObject(localization["stringUnit"]), msg.Other, appleCountArg(msg))
+			continue
+		}
+		delete(localization, "stringUnit")
+		variations := jsonObject(localization["variations"])
+		if variations == nil {
+			variations = map[string]any{}
+			localization["variations"] = variations
+		}
+		existing := jsonObject(variations["plural"])
+		plural := map[string]any{}
+		for _, category := range message.PluralCategories {
+			if form := msg.PluralForm(category); form != "" {
+				unit := jsonObject(jsonObject(existing[category])["stringUnit"])
+				plural[category] = map[string]any{"stringUnit": xcstringsUnit(unit, form, appleCountArg(msg))}
+			}
+		}
+		variations["plural"] = plural
+	}
+
+	var sb strings.Builder
+	if err := writeXCStringsValue(&sb, document, ""); err != nil {
+		return "", err
+	}
+	sb.WriteString("\n")
+	return sb.String(), nil
+}
+
+// readXCStrings reads and decodes an Xcode string catalog, keeping numbers as written.
+func readXCStrings(inputPath string) (map[string]any, error) {
+	content, err := os.ReadFile(inputPath)
+	if err != nil {
+		return nil, err
+	}
+	var catalog map[string]any
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	decoder.UseNumber()
+	if err := decoder.Decode(&catalog); err != nil {
+		return nil, fmt.Errorf("%s: %w", inputPath, err)
+	}
+	return catalog, nil
+}
+
+// xcstringsTranslated reports whether a localization holds a usable translation.
+// Localizations of the source language are always used.
+func xcstringsTranslated(localization map[string]any, source bool, opts XCStringsOptions) bool {
+	if localization == nil {
+		return false
+	}
+	if source {
+		return true
+	}
+	states := []any{jsonObject(localization["stringUnit"])["state"]}
+	for _, variation := range jsonObject(jsonObject(localization["variations"])["plural"]) {
+		states = append(states, jsonObject(jsonObject(variation)["stringUnit"])["state"])
+	}
+	for _, state := range states {
+		if state == "translated" || state == "needs_review" && opts.IncludeNeedsReview {
+			return true
+		}
+	}
+	return false
+}
+
+// xcstringsUnit returns the string unit for a value, whose {{.Count}} field is written as the given argument.
+// The existing unit is kept when its value did not change, including values that only differ in how their
+// placeholders are written, such as %lld and %1$lld.
+func xcstringsUnit(existing map[string]any, value string, count int) map[string]any {
+	existingValue, ok := existing["value"].(string)
+	if _, hasState := existing["state"].(string); ok && hasState && printfToTemplate(existingValue) == value {
+		return existing
+	}
+	return map[string]any{"state": "translated", "value": templateToApplePrintf(value, count)}
+}
+
+// jsonObject returns value as a JSON object, or nil when it is not one.
+func jsonObject(value any) map[string]any {
+	object, _ := value.(map[string]any)
+	return object
+}
+
+// writeXCStringsValue writes a decoded JSON value the way Xcode formats string catalogs,
+// with sorted keys, two space indentation and a space on both sides of the colons.
+func writeXCStringsValue(sb *strings.Builder, value any, indent string) error {
+	switch tt := value.(type) {
+	case map[string]any:
+		keys := make([]string, 0, len(tt))
+		for key := range tt {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		sb.WriteString("{\n")
+		if len(keys) == 0 {
+			sb.WriteString("\n")
+		}
+		for i, key := range keys {
+			if i > 0 {
+				sb.WriteString(",\n")
+			}
+			sb.WriteString(indent + "  ")
+			if err := writeXCStringsValue(sb, key, ""); err != nil {
+				return err
+			}
+			sb.WriteString(" : ")
+			if err := writeXCStringsValue(sb, tt[key], indent+"  "); err != nil {
+				return err
+			}
+		}
+		sb.WriteString("\n" + indent + "}")
+	case []any:
+		sb.WriteString("[\n")
+		for i, item := range tt {
+			if i > 0 {
+				sb.WriteString(",\n")
+			}
+			sb.WriteString(indent + "  ")
+			if err := writeXCStringsValue(sb, item, indent+"  "); err != nil {
+				return err
+			}
+		}
+		sb.WriteString("\n" + indent + "]")
+	default:
+		var buf bytes.Buffer
+		encoder := json.NewEncoder(&buf)
+		encoder.SetEscapeHTML(false)
+		if err := encoder.Encode(tt); err != nil {
+			return err
+		}
+		sb.WriteString(strings.TrimSuffix(buf.String(), "\n"))
+	}
+	return nil
+}
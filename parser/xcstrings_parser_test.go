@@ -0,0 +1,180 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/s-nix/mk2i18n/message"
+	"github.com/stretchr/testify/assert"
+)
+
+const xcstringsContent = `{
+  "sourceLanguage" : "en",
+  "strings" : {
+    "Hello" : {
+      "comment" : "Greeting on the start screen",
+      "localizations" : {
+        "de" : {
+          "stringUnit" : {
+            "state" : "translated",
+            "value" : "Hallo"
+          }
+        },
+        "fr" : {
+          "stringUnit" : {
+            "state" : "needs_review",
+            "value" : "Bonjour"
+          }
+        }
+      }
+    },
+    "items" : {
+      "localizations" : {
+        "de" : {
+          "variations" : {
+            "plural" : {
+              "one" : {
+                "stringUnit" : {
+                  "state" : "translated",
+                  "value" : "%lld Eintrag"
+                }
+              },
+              "other" : {
+                "stringUnit" : {
+                  "state" : "translated",
+                  "value" : "%lld Einträge"
+                }
+              }
+            }
+          }
+        },
+        "en" : {
+          "variations" : {
+            "plural" : {
+              "one" : {
+                "stringUnit" : {
+                  "state" : "translated",
+                  "value" : "%lld item"
+                }
+              },
+              "other" : {
+                "stringUnit" : {
+                  "state" : "translated",
+                  "value" : "%lld items"
+                }
+              }
+            }
+          }
+        }
+      }
+    },
+    "logo" : {
+      "shouldTranslate" : false
+    },
+    "removed" : {
+      "extractionState" : "stale"
+    }
+  },
+  "version" : "1.0"
+}
+`
+
+func TestFromXCStrings(t *testing.T) {
+	path := writeTempFile(t, "Localizable_*.xcstrings", xcstringsContent)
+
+	messages, err := FromXCStringsWithOptions(path, XCStringsOptions{Locale: "de"})
+	assert.NoError(t, err)
+	assert.Equal(t, []message.Message{
+		{ID: "Hello", Description: "Greeting on the start screen", Other: "Hallo"},
+		{ID: "items", One: `{{printf "%d" .Arg1}} Eintrag`, Other: `{{printf "%d" .Arg1}} Einträge`},
+	}, messages)
+
+	messages, err = FromXCStrings(path)
+	assert.NoError(t, err)
+	assert.Equal(t, []message.Message{
+		{ID: "Hello", Description: "Greeting on the start screen", Other: "Hello"},
+		{ID: "items", One: `{{printf "%d" .Arg1}} item`, Other: `{{printf "%d" .Arg1}} items`},
+	}, messages)
+
+	messages, err = FromXCStringsWithOptions(path, XCStringsOptions{Locale: "fr"})
+	assert.NoError(t, err)
+	assert.Equal(t, "Hello", messages[0].Other, "needs_review translations fall back to the source language")
+
+	messages, err = FromXCStringsWithOptions(path, XCStringsOptions{Locale: "fr", IncludeNeedsReview: true})
+	assert.NoError(t, err)
+	assert.Equal(t, "Bonjour", messages[0].Other)
+}
+
+func TestFromXCStrings_UnsupportedVariations(t *testing.T) {
+	path := writeTempFile(t, "Localizable_*.xcstrings", `{"sourceLanguage": "en", "strings": {"tap": {"localizations": {"en": {"variations": {"device": {}}}}}}}`)
+
+	_, err := FromXCStrings(path)
+	assert.ErrorContains(t, err, `"tap" uses device variations, which are not supported`)
+}
+
+func TestXCStringsLocales(t *testing.T) {
+	locales, err := XCStringsLocales(writeTempFile(t, "Localizable_*.xcstrings", xcstringsContent))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"de", "en", "fr"}, locales)
+}
+
+func TestToXCStrings(t *testing.T) {
+	output, err := ToXCStrings([]message.Message{
+		{ID: "greeting", Description: "Shown on start", Other: "Hello {{.Arg1}}"},
+	}, nil)
+	assert.NoError(t, err)
+
+	expected := `{
+  "sourceLanguage" : "en",
+  "strings" : {
+    "greeting" : {
+      "comment" : "Shown on start",
+      "extractionState" : "manual",
+      "localizations" : {
+        "en" : {
+          "stringUnit" : {
+            "state" : "translated",
+            "value" : "Hello %1$@"
+          }
+        }
+      }
+    }
+  },
+  "version" : "1.0"
+}
+`
+	assert.Equal(t, expected, output)
+}
+
+func TestToXCStrings_Merge(t *testing.T) {
+	output, err := ToXCStringsWithOptions([]message.Message{
+		{ID: "Hello", Other: "Salut"},
+		{ID: "items", One: `{{printf "%d" .Arg1}} élément`, Other: `{{printf "%d" .Arg1}} éléments`},
+	}, []byte(xcstringsContent), XCStringsOptions{Locale: "fr"})
+	assert.NoError(t, err)
+
+	path := writeTempFile(t, "Localizable_*.xcstrings", output)
+	french, err := FromXCStringsWithOptions(path, XCStringsOptions{Locale: "fr"})
+	assert.NoError(t, err)
+	assert.Equal(t, []message.Message{
+		{ID: "Hello", Description: "Greeting on the start screen", Other: "Salut"},
+		{ID: "items", One: `{{printf "%d" .Arg1}} élément`, Other: `{{printf "%d" .Arg1}} éléments`},
+	}, french)
+
+	german, err := FromXCStringsWithOptions(path, XCStringsOptions{Locale: "de"})
+	assert.NoError(t, err)
+	assert.Equal(t, "Hallo", german[0].Other, "other locales are kept")
+	assert.Contains(t, output, `"shouldTranslate" : false`, "other strings are kept")
+	assert.Contains(t, output, `"value" : "%1$lld éléments"`, "integer placeholders are written like Xcode does")
+
+	// Writing the catalog back unchanged keeps it as it was.
+	unchanged, err := ToXCStrings(nil, []byte(xcstringsContent))
+	assert.NoError(t, err)
+	assert.Equal(t, xcstringsContent, unchanged)
+
+	// Writing the messages read from the catalog keeps its placeholders, such as %lld, and the states of its string units.
+	german, err = FromXCStringsWithOptions(writeTempFile(t, "Localizable_*.xcstrings", xcstringsContent), XCStringsOptions{Locale: "de"})
+	assert.NoError(t, err)
+	rewritten, err := ToXCStringsWithOptions(german, []byte(xcstringsContent), XCStringsOptions{Locale: "de"})
+	assert.NoError(t, err)
+	assert.Equal(t, xcstringsContent, rewritten)
+}
@@ -0,0 +1,321 @@
+package parser
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/s-nix/mk2i18n/message"
+)
+
+// XLIFFOptions configures how XLIFF files are read and written.
+type XLIFFOptions struct {
+	// UseSource reads Other from the source element instead of the target element.
+	// When writing, only source elements are written, for files that are sent out for translation.
+	UseSource bool
+
+	// Version is the XLIFF version to write, "1.2" or "2.0". Defaults to "1.2".
+	Version string
+
+	// SourceLanguage is the source language written to the file. Defaults to "en".
+	SourceLanguage string
+
+	// TargetLanguage is the target language written to the file. Omitted when empty.
+	TargetLanguage string
+}
+
+type xliff12Document struct {
+	XMLName xml.Name    `xml:"xliff"`
+	Version string      `xml:"version,attr"`
+	XMLNS   string      `xml:"xmlns,attr"`
+	File    xliff12File `xml:"file"`
+}
+
+type xliff12File struct {
+	Original       string        `xml:"original,attr"`
+	SourceLanguage string        `xml:"source-language,attr"`
+	TargetLanguage string        `xml:"target-language,attr,omitempty"`
+	Datatype       string        `xml:"datatype,attr"`
+	Units          []xliff12Unit `xml:"body>trans-unit"`
+}
+
+type xliff12Unit struct {
+	ID     string    `xml:"id,attr"`
+	Source xliffText `xml:"source"`
+	Target xliffText `xml:"target,omitempty"`
+	Notes  []xmlText `xml:"note,omitempty"`
+}
+
+type xliff20Document struct {
+	XMLName xml.Name    `xml:"xliff"`
+	XMLNS   string      `xml:"xmlns,attr"`
+	Version string      `xml:"version,attr"`
+	SrcLang string      `xml:"srcLang,attr"`
+	TrgLang string      `xml:"trgLang,attr,omitempty"`
+	File    xliff20File `xml:"file"`
+}
+
+type xliff20File struct {
+	ID    string        `xml:"id,attr"`
+	Units []xliff20Unit `xml:"unit"`
+}
+
+type xliff20Unit struct {
+	ID       string           `xml:"id,attr"`
+	Notes    *xliff20Notes    `xml:"notes,omitempty"`
+	Segments []xliff20Segment `xml:"segment"`
+}
+
+type xliff20Notes struct {
+	Notes []xmlText `xml:"note"`
+}
+
+type xliff20Segment struct {
+	Source xliffText `xml:"source"`
+	Target xliffText `xml:"target,omitempty"`
+}
+
+// xliffText is the text of an XLIFF source or target element.
+// The placeholders x and ph become template fields named after their id, such as {{.name}}, or {{.Arg1}} for
+// numeric ids. The native codes of bpt, ept, it, bx, ex, sc and ec are dropped, and the text inside the markup
+// elements g, pc and mrk is kept.
+type xliffText string
+
+func (t *xliffText) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	var sb strings.Builder
+	depth := 1
+	for depth > 0 {
+		token, err := d.Token()
+		if err != nil {
+			return err
+		}
+		switch tt := token.(type) {
+		case xml.StartElement:
+			switch tt.Name.Local {
+			case "x", "ph":
+				id := xmlAttr(tt, "id")
+				switch {
+				case rXLIFFNumericID.MatchString(id):
+					sb.WriteString("{{.Arg" + id + "}}")
+				case rXLIFFFieldID.MatchString(id):
+					sb.WriteString("{{." + id + "}}")
+				default:
+					return fmt.Errorf("%s placeholder id %q is not a valid template field name", tt.Name.Local, id)
+				}
+				if err := d.Skip(); err != nil {
+					return err
+				}
+			case "bpt", "ept", "it", "bx", "ex", "sc", "ec":
+				if err := d.Skip(); err != nil {
+					return err
+				}
+			default:
+				depth++
+			}
+		case xml.EndElement:
+			depth--
+		case xml.CharData:
+			sb.Write(tt)
+		}
+	}
+	*t = xliffText(sb.String())
+	return nil
+}
+
+var (
+	// rXLIFFPluralID matches unit IDs of plural forms, such as items[one].
+	rXLIFFPluralID = regexp.MustCompile(`^(.*)\[(zero|one|two|few|many|other)\]$`)
+
+	// rXLIFFNumericID matches numeric placeholder ids, which become positional fields such as {{.Arg1}}.
+	rXLIFFNumericID = regexp.MustCompile(`^[0-9]+$`)
+
+	// rXLIFFFieldID matches placeholder ids that are valid template field names.
+	rXLIFFFieldID = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+)
+
+// ToXLIFF converts a slice of message.Message objects into an XLIFF 1.2 document with English as source language.
+func ToXLIFF(messages []message.Message) (string, error) {
+	return ToXLIFFWithOptions(messages, XLIFFOptions{})
+}
+
+// ToXLIFFWithOptions converts a slice of message.Message objects into an XLIFF document using the given options.
+// Every message becomes a unit with its ID, Other as source and target, and Description as note.
+// Plural messages become one unit per plural form, with IDs such as items[one] and items[other].
+func ToXLIFFWithOptions(messages []message.Message, opts XLIFFOptions) (string, error) {
+	sourceLanguage := opts.SourceLanguage
+	if sourceLanguage == "" {
+		sourceLanguage = "en"
+	}
+
+	type xliffUnit struct {
+		id, text, note string
+	}
+	var units []xliffUnit
+	for _, msg := range messages {
+		if !msg.IsPlural() {
+			units = append(units, xliffUnit{msg.ID, msg.Other, msg.Description})
+			continue
+		}
+		for _, category := range message.PluralCategories {
+			if form := msg.PluralForm(category); form != "" {
+				units = append(units, xliffUnit{fmt.Sprintf("%s[%s]", msg.ID, category), form, msg.Description})
+			}
+		}
+	}
+
+	var document any
+	switch opts.Version {
+	case "", "1.2":
+		file := xliff12File{
+			Original:       "messages",
+			SourceLanguage: sourceLanguage,
+			TargetLanguage: opts.TargetLanguage,
+			Datatype:       "plaintext",
+		}
+		for _, unit := range units {
+			out := xliff12Unit{ID: unit.id, Source: xliffText(unit.text)}
+			if !opts.UseSource {
+				out.Target = xliffText(unit.text)
+			}
+			if unit.note != "" {
+				out.Notes = []xmlText{xmlText(unit.note)}
+			}
+			file.Units = append(file.Units, out)
+		}
+		document = xliff12Document{Version: "1.2", XMLNS: "urn:oasis:names:tc:xliff:document:1.2", File: file}
+	case "2.0":
+		file := xliff20File{ID: "messages"}
+		for _, unit := range units {
+			segment := xliff20Segment{Source: xliffText(unit.text)}
+			if !opts.UseSource {
+				segment.Target = xliffText(unit.text)
+			}
+			out := xliff20Unit{ID: unit.id, Segments: []xliff20Segment{segment}}
+			if unit.note != "" {
+				out.Notes = &xliff20Notes{Notes: []xmlText{xmlText(unit.note)}}
+			}
+			file.Units = append(file.Units, out)
+		}
+		document = xliff20Document{
+			XMLNS:   "urn:oasis:names:tc:xliff:document:2.0",
+			Version: "2.0",
+			SrcLang: sourceLanguage,
+			TrgLang: opts.TargetLanguage,
+			File:    file,
+		}
+	default:
+		return "", fmt.Errorf("unsupported XLIFF version: %s", opts.Version)
+	}
+
+	content, err := xml.MarshalIndent(document, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return xml.Header + string(content) + "\n", nil
+}
+
+// FromXLIFF reads an XLIFF 1.2 or 2.0 file into messages.
+func FromXLIFF(inputPath string) ([]message.Message, error) {
+	return FromXLIFFWithOptions(inputPath, XLIFFOptions{})
+}
+
+// FromXLIFFWithOptions reads an XLIFF 1.2 or 2.0 file into messages using the given options.
+// The id of each trans-unit (1.2) or unit (2.0) is the message ID, the target is Other, falling back to
+// the source when the target is empty, and notes become the Description.
+// Units with IDs such as items[one] and items[other] are combined into one plural message.
+func FromXLIFFWithOptions(inputPath string, opts XLIFFOptions) ([]message.Message, error) {
+	content, err := os.ReadFile(inputPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var messages []message.Message
+	plurals := map[string]*message.Message{}
+	addUnit := func(id string, source, target xliffText, notes []xmlText) {
+		text := string(target)
+		if opts.UseSource || text == "" {
+			text = string(source)
+		}
+		var descriptions []string
+		for _, note := range notes {
+			descriptions = append(descriptions, strings.TrimSpace(string(note)))
+		}
+		description := strings.Join(descriptions, "\n")
+
+		if match := rXLIFFPluralID.FindStringSubmatch(id); match != nil {
+			msg, exists := plurals[match[1]]
+			if !exists {
+				msg = &message.Message{ID: match[1], Description: description}
+				plurals[match[1]] = msg
+			}
+			msg.SetPluralForm(match[2], text)
+			return
+		}
+		messages = append(messages, message.Message{ID: id, Description: description, Other: text})
+	}
+
+	decoder := xml.NewDecoder(bytes.NewReader(content))
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		start, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		switch start.Name.Local {
+		case "trans-unit":
+			var unit xliff12Unit
+			if err := decoder.DecodeElement(&unit, &start); err != nil {
+				return nil, fmt.Errorf("%s: %w", inputPath, err)
+			}
+			addUnit(unit.ID, unit.Source, unit.Target, unit.Notes)
+		case "unit":
+			var unit xliff20Unit
+			if err := decoder.DecodeElement(&unit, &start); err != nil {
+				return nil, fmt.Errorf("%s: %w", inputPath, err)
+			}
+			var source, target xliffText
+			for _, segment := range unit.Segments {
+				source += segment.Source
+				target += segment.Target
+			}
+			var notes []xmlText
+			if unit.Notes != nil {
+				notes = unit.Notes.Notes
+			}
+			addUnit(unit.ID, source, target, notes)
+		}
+	}
+
+	for _, msg := range plurals {
+		if msg.Other == "" {
+			// Without an "other" form the units are not a plural group, keep them as they are.
+			for _, category := range message.PluralCategories {
+				if form := msg.PluralForm(category); form != "" {
+					id := fmt.Sprintf("%s[%s]", msg.ID, category)
+					messages = append(messages, message.Message{ID: id, Description: msg.Description, Other: form})
+				}
+			}
+			continue
+		}
+		messages = append(messages, *msg)
+	}
+
+	if len(messages) == 0 {
+		return nil, nil
+	}
+	sort.Slice(messages, func(i, j int) bool {
+		return messages[i].ID < messages[j].ID
+	})
+	return messages, nil
+}
@@ -0,0 +1,194 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/s-nix/mk2i18n/message"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFromXLIFF_Version12(t *testing.T) {
+	content := `<?xml version="1.0" encoding="UTF-8"?>
+<xliff version="1.2" xmlns="urn:oasis:names:tc:xliff:document:1.2">
+  <file original="app" source-language="en" target-language="de" datatype="plaintext">
+    <body>
+      <trans-unit id="greeting">
+        <source>Hello</source>
+        <target>Hallo</target>
+        <note>A greeting message</note>
+      </trans-unit>
+      <group id="menu">
+        <trans-unit id="menu.open">
+          <source>Open <x id="1"/></source>
+          <target><x id="1"/> <g id="2">öffnen</g><bpt id="3">&lt;b&gt;</bpt><ept id="3">&lt;/b&gt;</ept></target>
+        </trans-unit>
+        <trans-unit id="inbox">
+          <source>Hello <x id="name"/>, you have <ph id="1">%d</ph> mails</source>
+          <target>Hallo <x id="name"/>, du hast <ph id="1">%d</ph> Mails</target>
+        </trans-unit>
+      </group>
+      <trans-unit id="untranslated">
+        <source>Not yet</source>
+      </trans-unit>
+      <trans-unit id="items[one]">
+        <source>one item</source>
+        <target>ein Artikel</target>
+      </trans-unit>
+      <trans-unit id="items[other]">
+        <source>{{.Count}} items</source>
+        <target>{{.Count}} Artikel</target>
+      </trans-unit>
+    </body>
+  </file>
+</xliff>`
+	path := writeTempFile(t, "test_*.xlf", content)
+
+	messages, err := FromXLIFF(path)
+	assert.NoError(t, err)
+	expectedMessages := []message.Message{
+		{ID: "greeting", Description: "A greeting message", Other: "Hallo"},
+		{ID: "inbox", Other: "Hallo {{.name}}, du hast {{.Arg1}} Mails"},
+		{ID: "items", One: "ein Artikel", Other: "{{.Count}} Artikel"},
+		{ID: "menu.open", Other: "{{.Arg1}} öffnen"},
+		{ID: "untranslated", Other: "Not yet"},
+	}
+	assert.Equal(t, expectedMessages, messages)
+
+	messages, err = FromXLIFFWithOptions(path, XLIFFOptions{UseSource: true})
+	assert.NoError(t, err)
+	expectedMessages = []message.Message{
+		{ID: "greeting", Description: "A greeting message", Other: "Hello"},
+		{ID: "inbox", Other: "Hello {{.name}}, you have {{.Arg1}} mails"},
+		{ID: "items", One: "one item", Other: "{{.Count}} items"},
+		{ID: "menu.open", Other: "Open {{.Arg1}}"},
+		{ID: "untranslated", Other: "Not yet"},
+	}
+	assert.Equal(t, expectedMessages, messages)
+}
+
+func TestFromXLIFF_Version20(t *testing.T) {
+	content := `<?xml version="1.0" encoding="UTF-8"?>
+<xliff xmlns="urn:oasis:names:tc:xliff:document:2.0" version="2.0" srcLang="en" trgLang="fr">
+  <file id="f1">
+    <unit id="greeting">
+      <notes>
+        <note>A greeting message</note>
+        <note>Keep it short</note>
+      </notes>
+      <segment>
+        <source>Hello</source>
+        <target>Bonjour</target>
+      </segment>
+    </unit>
+    <group id="g1">
+      <unit id="farewell">
+        <segment>
+          <source>Goodbye. </source>
+          <target>Au revoir. </target>
+        </segment>
+        <segment>
+          <source>See you, <ph id="user"/>!</source>
+          <target>À bientôt, <pc id="1"><ph id="user"/></pc> !</target>
+        </segment>
+      </unit>
+    </group>
+    <unit id="lonely[one]">
+      <segment>
+        <source>just one</source>
+      </segment>
+    </unit>
+  </file>
+</xliff>`
+	path := writeTempFile(t, "test_*.xlf", content)
+
+	messages, err := FromXLIFF(path)
+	assert.NoError(t, err)
+	expectedMessages := []message.Message{
+		{ID: "farewell", Other: "Au revoir. À bientôt, {{.user}} !"},
+		{ID: "greeting", Description: "A greeting message\nKeep it short", Other: "Bonjour"},
+		{ID: "lonely[one]", Other: "just one"},
+	}
+	assert.Equal(t, expectedMessages, messages)
+
+	invalid := `<xliff version="1.2"><file><body><trans-unit id="a"><source><x id="PH-1"/></source></trans-unit></body></file></xliff>`
+	_, err = FromXLIFF(writeTempFile(t, "test_*.xlf", invalid))
+	assert.ErrorContains(t, err, `x placeholder id "PH-1" is not a valid template field name`)
+}
+
+func TestToXLIFF(t *testing.T) {
+	messages := []message.Message{
+		{ID: "greeting", Description: "A greeting message", Other: "Hallo & willkommen"},
+		{ID: "items", One: "ein Artikel", Other: "{{.Count}} Artikel"},
+	}
+
+	expected12 := `<?xml version="1.0" encoding="UTF-8"?>
+<xliff version="1.2" xmlns="urn:oasis:names:tc:xliff:document:1.2">
+  <file original="messages" source-language="en" target-language="de" datatype="plaintext">
+    <body>
+      <trans-unit id="greeting">
+        <source>Hallo &amp; willkommen</source>
+        <target>Hallo &amp; willkommen</target>
+        <note>A greeting message</note>
+      </trans-unit>
+      <trans-unit id="items[one]">
+        <source>ein Artikel</source>
+        <target>ein Artikel</target>
+      </trans-unit>
+      <trans-unit id="items[other]">
+        <source>{{.Count}} Artikel</source>
+        <target>{{.Count}} Artikel</target>
+      </trans-unit>
+    </body>
+  </file>
+</xliff>
+`
+	output, err := ToXLIFFWithOptions(messages, XLIFFOptions{TargetLanguage: "de"})
+	assert.NoError(t, err)
+	assert.Equal(t, expected12, output)
+
+	expected20 := `<?xml version="1.0" encoding="UTF-8"?>
+<xliff xmlns="urn:oasis:names:tc:xliff:document:2.0" version="2.0" srcLang="de">
+  <file id="messages">
+    <unit id="greeting">
+      <notes>
+        <note>A greeting message</note>
+      </notes>
+      <segment>
+        <source>Hallo &amp; willkommen</source>
+      </segment>
+    </unit>
+    <unit id="items[one]">
+      <segment>
+        <source>ein Artikel</source>
+      </segment>
+    </unit>
+    <unit id="items[other]">
+      <segment>
+        <source>{{.Count}} Artikel</source>
+      </segment>
+    </unit>
+  </file>
+</xliff>
+`
+	output, err = ToXLIFFWithOptions(messages, XLIFFOptions{Version: "2.0", SourceLanguage: "de", UseSource: true})
+	assert.NoError(t, err)
+	assert.Equal(t, expected20, output)
+
+	_, err = ToXLIFFWithOptions(messages, XLIFFOptions{Version: "3.0"})
+	assert.ErrorContains(t, err, "unsupported XLIFF version")
+}
+
+func TestToXLIFF_RoundTrip(t *testing.T) {
+	messages := []message.Message{
+		{ID: "greeting", Description: "A greeting message", Other: "Hello"},
+		{ID: "items", Description: "Item count", One: "one item", Other: "{{.Count}} items"},
+	}
+	for _, version := range []string{"1.2", "2.0"} {
+		output, err := ToXLIFFWithOptions(messages, XLIFFOptions{Version: version, TargetLanguage: "en"})
+		assert.NoError(t, err)
+
+		parsed, err := FromXLIFF(writeTempFile(t, "test_*.xlf", output))
+		assert.NoError(t, err)
+		assert.Equal(t, messages, parsed, version)
+	}
+}
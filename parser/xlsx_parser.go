@@ -0,0 +1,244 @@
+package parser
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/s-nix/mk2i18n/message"
+)
+
+// xlsxWorkbook is the xl/workbook.xml part of an XLSX workbook.
+type xlsxWorkbook struct {
+	Sheets []struct {
+		Name string `xml:"name,attr"`
+		ID   string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+	} `xml:"sheets>sheet"`
+}
+
+// xlsxRelationships is the xl/_rels/workbook.xml.rels part, locating the worksheet parts of the workbook.
+type xlsxRelationships struct {
+	Relationships []struct {
+		ID     string `xml:"Id,attr"`
+		Target string `xml:"Target,attr"`
+	} `xml:"Relationship"`
+}
+
+// xlsxString is a shared or inline string, made of plain text or of rich text runs.
+type xlsxString struct {
+	Text string `xml:"t"`
+	Runs []struct {
+		Text string `xml:"t"`
+	} `xml:"r"`
+}
+
+// xlsxWorksheet is a worksheet part of an XLSX workbook.
+type xlsxWorksheet struct {
+	Rows []struct {
+		Number int `xml:"r,attr"`
+		Cells  []struct {
+			Reference string     `xml:"r,attr"`
+			Type      string     `xml:"t,attr"`
+			Value     string     `xml:"v"`
+			Inline    xlsxString `xml:"is"`
+		} `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+var (
+	// rXLSXEscape matches the escaped characters of Office Open XML strings, such as _x000D_.
+	rXLSXEscape = regexp.MustCompile(`_x([0-9A-Fa-f]{4})_`)
+
+	// rCellColumn matches the column letters of a cell reference, such as AB in AB12.
+	rCellColumn = regexp.MustCompile(`^[A-Z]+`)
+)
+
+// FromXLSX reads the first worksheet of an Excel .xlsx workbook into messages, with the column layout of its header row.
+func FromXLSX(inputPath string) ([]message.Message, error) {
+	return FromXLSXWithOptions(inputPath, CSVOptions{})
+}
+
+// FromXLSXWithOptions reads a worksheet of an Excel .xlsx workbook into messages, with one message per row.
+//
+// The worksheet is Sheet, or the first sheet of the workbook. Rows are mapped to messages the same way as by
+// FromCSVWithOptions, using the Columns, Locale and header row detection of opts; errors refer to the row numbers
+// of the sheet. Shared, inline and rich text strings are read as their text, and numbers and booleans as their value.
+func FromXLSXWithOptions(inputPath string, opts CSVOptions) ([]message.Message, error) {
+	rows, err := readXLSXSheet(inputPath, opts.Sheet)
+	if err != nil {
+		return nil, err
+	}
+	return messagesFromRecords(inputPath, xlsxRecords(rows), opts)
+}
+
+// XLSXLocales returns the locales of the locale columns of a worksheet of an .xlsx workbook, in column order.
+// The columns are Columns, or those of the header row.
+func XLSXLocales(inputPath string, opts CSVOptions) ([]string, error) {
+	rows, err := readXLSXSheet(inputPath, opts.Sheet)
+	if err != nil {
+		return nil, err
+	}
+	return localesFromRecords(inputPath, xlsxRecords(rows), opts)
+}
+
+// xlsxRow is a row of cell values with its row number.
+type xlsxRow struct {
+	number int
+	cells  []string
+}
+
+// xlsxRecords returns a recordFunc reading the given rows.
+func xlsxRecords(rows []xlsxRow) recordFunc {
+	return func() ([]string, int, error) {
+		if len(rows) == 0 {
+			return nil, 0, io.EOF
+		}
+		row := rows[0]
+		rows = rows[1:]
+		return row.cells, row.number, nil
+	}
+}
+
+// readXLSXSheet reads the cell values of the named worksheet, or of the first one when sheet is empty.
+func readXLSXSheet(inputPath string, sheet string) ([]xlsxRow, error) {
+	archive, err := zip.OpenReader(inputPath)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", inputPath, err)
+	}
+	defer func() {
+		_ = archive.Close()
+	}()
+	parts := map[string]*zip.File{}
+	for _, file := range archive.File {
+		parts[file.Name] = file
+	}
+
+	var workbook xlsxWorkbook
+	if err := decodeXLSXPart(parts, "xl/workbook.xml", &workbook); err != nil {
+		return nil, fmt.Errorf("%s: %w", inputPath, err)
+	}
+	var relationships xlsxRelationships
+	if err := decodeXLSXPart(parts, "xl/_rels/workbook.xml.rels", &relationships); err != nil {
+		return nil, fmt.Errorf("%s: %w", inputPath, err)
+	}
+	var sharedStrings struct {
+		Items []xlsxString `xml:"si"`
+	}
+	if _, ok := parts["xl/sharedStrings.xml"]; ok {
+		if err := decodeXLSXPart(parts, "xl/sharedStrings.xml", &sharedStrings); err != nil {
+			return nil, fmt.Errorf("%s: %w", inputPath, err)
+		}
+	}
+
+	if len(workbook.Sheets) == 0 {
+		return nil, fmt.Errorf("%s: workbook has no sheets", inputPath)
+	}
+	relationshipID := ""
+	var names []string
+	for _, candidate := range workbook.Sheets {
+		if sheet == "" || candidate.Name == sheet {
+			relationshipID = candidate.ID
+			break
+		}
+		names = append(names, candidate.Name)
+	}
+	if relationshipID == "" {
+		return nil, fmt.Errorf("%s: no sheet named %q, the sheets are %s", inputPath, sheet, strings.Join(names, ", "))
+	}
+	sheetPart := ""
+	for _, relationship := range relationships.Relationships {
+		if relationship.ID == relationshipID {
+			sheetPart = relationship.Target
+			if strings.HasPrefix(sheetPart, "/") {
+				sheetPart = sheetPart[1:]
+			} else {
+				sheetPart = path.Join("xl", sheetPart)
+			}
+		}
+	}
+	var worksheet xlsxWorksheet
+	if err := decodeXLSXPart(parts, sheetPart, &worksheet); err != nil {
+		return nil, fmt.Errorf("%s: %w", inputPath, err)
+	}
+
+	var rows []xlsxRow
+	for index, sheetRow := range worksheet.Rows {
+		row := xlsxRow{number: sheetRow.Number}
+		if row.number == 0 {
+			row.number = index + 1
+		}
+		for _, cell := range sheetRow.Cells {
+			column := len(row.cells)
+			if letters := rCellColumn.FindString(cell.Reference); letters != "" {
+				column = 0
+				for _, letter := range letters {
+					column = column*26 + int(letter-'A') + 1
+				}
+				column--
+			}
+
+			var value string
+			switch cell.Type {
+			case "s":
+				index, err := strconv.Atoi(cell.Value)
+				if err != nil || index < 0 || index >= len(sharedStrings.Items) {
+					return nil, fmt.Errorf("%s:%d: cell %s refers to unknown shared string %q", inputPath, row.number, cell.Reference, cell.Value)
+				}
+				value = sharedStrings.Items[index].text()
+			case "inlineStr":
+				value = cell.Inline.text()
+			case "b":
+				value = map[string]string{"0": "FALSE", "1": "TRUE"}[cell.Value]
+			default:
+				value = cell.Value
+			}
+
+			for len(row.cells) <= column {
+				row.cells = append(row.cells, "")
+			}
+			row.cells[column] = value
+		}
+		rows = append(rows, row)
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		return rows[i].number < rows[j].number
+	})
+	return rows, nil
+}
+
+// text returns the text of a shared or inline string, decoding escaped characters such as _x000D_.
+func (s xlsxString) text() string {
+	text := s.Text
+	for _, run := range s.Runs {
+		text += run.Text
+	}
+	return rXLSXEscape.ReplaceAllStringFunc(text, func(escape string) string {
+		code, _ := strconv.ParseUint(escape[2:6], 16, 32)
+		return string(rune(code))
+	})
+}
+
+// decodeXLSXPart decodes the XML part with the given name from the workbook archive.
+func decodeXLSXPart(parts map[string]*zip.File, name string, v any) error {
+	file, ok := parts[name]
+	if !ok {
+		return fmt.Errorf("missing workbook part %s", name)
+	}
+	reader, err := file.Open()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = reader.Close()
+	}()
+	if err := xml.NewDecoder(reader).Decode(v); err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package parser
+
+import (
+	"archive/zip"
+	"os"
+	"testing"
+
+	"github.com/s-nix/mk2i18n/message"
+	"github.com/stretchr/testify/assert"
+)
+
+// writeXLSXFile writes a workbook with a Notes sheet and a Messages sheet holding the given sheet data.
+func writeXLSXFile(t *testing.T, sharedStrings string, sheetData string) string {
+	tmpFile, err := os.CreateTemp("", "messages_*.xlsx")
+	assert.NoError(t, err)
+	t.Cleanup(func() {
+		_ = os.Remove(tmpFile.Name())
+	})
+
+	parts := map[string]string{
+		"xl/workbook.xml": `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">
+<sheets><sheet name="Notes" sheetId="1" r:id="rId1"/><sheet name="Messages" sheetId="2" r:id="rId2"/></sheets>
+</workbook>`,
+		"xl/_rels/workbook.xml.rels": `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>
+<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="/xl/worksheets/sheet2.xml"/>
+</Relationships>`,
+		"xl/sharedStrings.xml": `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<sst xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` + sharedStrings + `</sst>`,
+		"xl/worksheets/sheet1.xml": `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>
+<row r="1"><c r="A1" t="inlineStr"><is><t>Not for translation</t></is></c></row>
+</sheetData></worksheet>`,
+		"xl/worksheets/sheet2.xml": `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>` + sheetData + `</sheetData></worksheet>`,
+	}
+	archive := zip.NewWriter(tmpFile)
+	for name, content := range parts {
+		writer, err := archive.Create(name)
+		assert.NoError(t, err)
+		_, err = writer.Write([]byte(content))
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, archive.Close())
+	assert.NoError(t, tmpFile.Close())
+	return tmpFile.Name()
+}
+
+const xlsxSharedStrings = `<si><t>id</t></si><si><t>description</t></si><si><t>en</t></si><si><t>de</t></si>` +
+	`<si><t>greeting</t></si><si><r><rPr><b/></rPr><t>Hello</t></r><r><t xml:space="preserve">, world</t></r></si>` +
+	`<si><t>Hallo_x000D_
+Welt</t><rPh sb="0" eb="1"><t>ignored</t></rPh></si>`
+
+const xlsxSheetData = `<row r="1"><c r="A1" t="s"><v>0</v></c><c r="B1" t="s"><v>1</v></c><c r="C1" t="s"><v>2</v></c><c r="D1" t="s"><v>3</v></c></row>
+<row r="2"><c r="A2" t="s"><v>4</v></c><c r="C2" t="s"><v>5</v></c><c r="D2" t="s"><v>6</v></c></row>
+<row r="4"><c r="A4" t="inlineStr"><is><t>count</t></is></c><c r="B4" t="inlineStr"><is><t>A number</t></is></c><c r="C4"><v>42</v></c><c r="D4" t="b"><v>1</v></c></row>`
+
+func TestFromXLSX(t *testing.T) {
+	path := writeXLSXFile(t, xlsxSharedStrings, xlsxSheetData)
+
+	messages, err := FromXLSXWithOptions(path, CSVOptions{Sheet: "Messages", Locale: "de"})
+	assert.NoError(t, err)
+	assert.Equal(t, []message.Message{
+		{ID: "count", Description: "A number", Other: "TRUE"},
+		{ID: "greeting", Other: "Hallo\r\nWelt"},
+	}, messages)
+
+	messages, err = FromXLSXWithOptions(path, CSVOptions{Sheet: "Messages", Columns: []string{"id", "", "other"}})
+	assert.NoError(t, err)
+	assert.Equal(t, []message.Message{{ID: "count", Other: "42"}, {ID: "greeting", Other: "Hello, world"}}, messages)
+
+	locales, err := XLSXLocales(path, CSVOptions{Sheet: "Messages"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"en", "de"}, locales)
+
+	messages, err = FromXLSX(path)
+	assert.NoError(t, err)
+	assert.Equal(t, []message.Message{{ID: "Not for translation"}}, messages)
+
+	_, err = FromXLSXWithOptions(writeXLSXFile(t, "", `<row r="2"><c r="B2" t="inlineStr"><is><t>No ID</t></is></c></row>`), CSVOptions{Sheet: "Messages"})
+	assert.ErrorContains(t, err, ":2: row has no message ID")
+
+	_, err = FromXLSXWithOptions(path, CSVOptions{Sheet: "Other"})
+	assert.ErrorContains(t, err, `no sheet named "Other", the sheets are Notes, Messages`)
+
+	_, err = FromXLSXWithOptions(writeXLSXFile(t, "", `<row r="3"><c r="A3" t="s"><v>7</v></c></row>`), CSVOptions{Sheet: "Messages"})
+	assert.ErrorContains(t, err, `:3: cell A3 refers to unknown shared string "7"`)
+}
@@ -1,8 +1,10 @@
 package parser
 
 import (
+	"bytes"
 	"encoding/xml"
 	"fmt"
+	"io"
 	"os"
 	"regexp"
 	"slices"
@@ -12,6 +14,92 @@ import (
 	"github.com/s-nix/mk2i18n/message"
 )
 
+// XMLFormat names a flavor of XML input.
+type XMLFormat string
+
+const (
+	// XMLFormatAuto detects the flavor from the content of the file, see DetectXMLFormat.
+	XMLFormatAuto XMLFormat = ""
+
+	// XMLFormatGeneric reads any XML document as a tree of elements, see FromXML.
+	XMLFormatGeneric XMLFormat = "generic"
+
+	// XMLFormatAndroid reads Android string resources, see FromAndroidXML.
+	XMLFormatAndroid XMLFormat = "android"
+
+	// XMLFormatProperties reads Java properties stored with Properties.storeToXML, see FromPropertiesXML.
+	XMLFormatProperties XMLFormat = "properties"
+)
+
+// DetectXMLFormat inspects an XML file and returns its flavor.
+// A file named *.properties.xml or declaring the Java properties DTD is a Java properties file, and a resources
+// root element with a string, string-array or plurals child carrying a name attribute is an Android resource
+// file; everything else is generic XML.
+func DetectXMLFormat(inputPath string) (XMLFormat, error) {
+	if strings.HasSuffix(strings.ToLower(inputPath), ".properties.xml") {
+		return XMLFormatProperties, nil
+	}
+	content, err := os.ReadFile(inputPath)
+	if err != nil {
+		return XMLFormatAuto, err
+	}
+	decoder := xml.NewDecoder(bytes.NewReader(content))
+	depth := 0
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return XMLFormatGeneric, nil
+		}
+		if err != nil {
+			return XMLFormatAuto, err
+		}
+		switch tt := token.(type) {
+		case xml.Directive:
+			if depth == 0 && bytes.Contains(tt, []byte(propertiesDTD)) {
+				return XMLFormatProperties, nil
+			}
+		case xml.StartElement:
+			depth++
+			if depth == 1 && tt.Name.Local != "resources" {
+				return XMLFormatGeneric, nil
+			}
+			if depth == 2 && xmlAttr(tt, "name") != "" {
+				switch tt.Name.Local {
+				case "string", "string-array", "plurals":
+					return XMLFormatAndroid, nil
+				}
+			}
+		case xml.EndElement:
+			depth--
+		}
+	}
+}
+
+// xmlText is the text content of an XML element.
+// Inline markup, such as placeholders, is dropped and only its text is kept.
+type xmlText string
+
+func (t *xmlText) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	var sb strings.Builder
+	depth := 1
+	for depth > 0 {
+		token, err := d.Token()
+		if err != nil {
+			return err
+		}
+		switch tt := token.(type) {
+		case xml.StartElement:
+			depth++
+		case xml.EndElement:
+			depth--
+		case xml.CharData:
+			sb.Write(tt)
+		}
+	}
+	*t = xmlText(sb.String())
+	return nil
+}
+
 type XMLFile struct {
 	Data map[string]any
 }
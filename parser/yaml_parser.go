@@ -2,6 +2,7 @@ package parser
 
 import (
 	"os"
+	"strings"
 
 	"github.com/s-nix/mk2i18n/message"
 	"gopkg.in/yaml.v3"
@@ -19,14 +20,39 @@ func ToYAML(messages []message.Message) (string, error) {
 	return result, nil
 }
 
+// ToNestedYAML converts a slice of message.Message objects into plain nested YAML rather than go-i18n
+// message objects. See UnflattenMessages for how the messages are nested.
+func ToNestedYAML(messages []message.Message) (string, error) {
+	data, err := UnflattenMessages(messages)
+	if err != nil {
+		return "", err
+	}
+	var sb strings.Builder
+	encoder := yaml.NewEncoder(&sb)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(data); err != nil {
+		return "", err
+	}
+	if err := encoder.Close(); err != nil {
+		return "", err
+	}
+	return sb.String(), nil
+}
+
+// FromYAML reads a YAML file and flattens it into messages.
 func FromYAML(inputPath string) ([]message.Message, error) {
+	return FromYAMLWithOptions(inputPath, FlattenOptions{})
+}
+
+// FromYAMLWithOptions reads a YAML file and flattens it into messages using the given options.
+func FromYAMLWithOptions(inputPath string, opts FlattenOptions) ([]message.Message, error) {
 	var messages []message.Message
 	var data map[string]any
 	err := DecodeYAMLFile(inputPath, &data)
 	if err != nil {
 		return nil, err
 	}
-	FlattenDataToMessages(data, &messages, "")
+	FlattenDataToMessagesWithOptions(data, &messages, "", opts)
 	if len(messages) == 0 {
 		return nil, nil
 	}
@@ -58,23 +58,13 @@ farewell:
 
 	expectedMessages := []message.Message{
 		{
-			ID:          "farewell.description",
-			Description: "",
-			Other:       "A friendly farewell",
-		},
-		{
-			ID:          "farewell.other",
-			Description: "",
+			ID:          "farewell",
+			Description: "A friendly farewell",
 			Other:       "Goodbye, World!",
 		},
 		{
-			ID:          "greeting.description",
-			Description: "",
-			Other:       "A friendly greeting",
-		},
-		{
-			ID:          "greeting.other",
-			Description: "",
+			ID:          "greeting",
+			Description: "A friendly greeting",
 			Other:       "Hello, World!",
 		},
 	}